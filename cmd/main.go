@@ -1,11 +1,15 @@
 package main
 
 import (
+	"log"
+
 	"github.com/nomoyu/go-gin-framework/nomoyu"
 )
 
 func main() {
-	nomoyu.Start().
+	if err := nomoyu.New().
 		WithSwagger(). // 未来拓展模块
-		Run(":8080")
+		Run(":8080"); err != nil {
+		log.Fatal(err)
+	}
 }
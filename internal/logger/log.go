@@ -24,7 +24,10 @@ var (
 	reqBind sync.Map // map[uint64]*zap.Logger（按 goroutine 绑定 traceID）
 )
 
-func InitLogger() { InitLoggerWithConfig(config.Conf.Log.Path, config.Conf.Log.Level) }
+func InitLogger() {
+	conf := config.Current().Log
+	InitLoggerWithConfig(conf.Path, conf.Level)
+}
 
 // Internal: used by framework middleware only.
 func InitLoggerWithConfig(logPath, level string) {
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/pkg/errorcode"
+	"github.com/nomoyu/go-gin-framework/pkg/response"
+)
+
+// LoopbackOnly 只允许本机访问
+var LoopbackOnly = []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8"), netip.MustParsePrefix("::1/128")}
+
+// ParseAllowIPs 解析 IP 或 CIDR 列表
+func ParseAllowIPs(list []string) ([]netip.Prefix, error) {
+	out := make([]netip.Prefix, 0, len(list))
+	for _, s := range list {
+		if p, err := netip.ParsePrefix(s); err == nil {
+			out = append(out, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP or CIDR %q", s)
+		}
+		out = append(out, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return out, nil
+}
+
+// IPAllowed ip 是否在 allow 中，allow 为空时不限制
+func IPAllowed(allow []netip.Prefix, ip string) bool {
+	if len(allow) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range allow {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowIPs 按直连地址（不信任 X-Forwarded-For）拒绝不在 allow 中的请求，返回 403 与 msg
+func AllowIPs(allow []netip.Prefix, msg string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !IPAllowed(allow, c.RemoteIP()) {
+			c.AbortWithStatusJSON(http.StatusForbidden, response.Response{Code: errorcode.Forbidden.Code, Msg: msg})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/pkg/errorcode"
+	"github.com/nomoyu/go-gin-framework/pkg/response"
+)
+
+// RateLimiter 按客户端地址（c.ClientIP()）的令牌桶限流，限额可在运行中通过 SetLimit 调整
+type RateLimiter struct {
+	mu      sync.Mutex
+	enabled bool
+	rate    float64 // 每秒补充的令牌数
+	burst   float64
+	buckets map[string]*bucket
+	swept   time.Time
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter 创建限流器；enabled 为 false 时放行所有请求
+func NewRateLimiter(enabled bool, rate float64, burst int) *RateLimiter {
+	l := &RateLimiter{buckets: map[string]*bucket{}, now: time.Now}
+	l.SetLimit(enabled, rate, burst)
+	return l
+}
+
+// SetLimit 调整限额，已有客户端的令牌数按新容量截断
+func (l *RateLimiter) SetLimit(enabled bool, rate float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.enabled, l.rate, l.burst = enabled, rate, float64(burst)
+	if !enabled {
+		l.buckets = map[string]*bucket{}
+	}
+	for _, b := range l.buckets {
+		b.tokens = math.Min(b.tokens, l.burst)
+	}
+}
+
+// Allow 取一个令牌；不允许时返回需要等待的时间
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.enabled {
+		return true, 0
+	}
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+}
+
+// sweep 每分钟清理一次已经补满的令牌桶，避免客户端地址无限累积
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < time.Minute {
+		return
+	}
+	l.swept = now
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Handler 超出限额时返回 429 与 Retry-After
+func (l *RateLimiter) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ok, wait := l.Allow(c.ClientIP())
+		if ok {
+			c.Next()
+			return
+		}
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		ec := errorcode.TooManyRequests
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, response.Response{Code: ec.Code, Msg: ec.Msg})
+	}
+}
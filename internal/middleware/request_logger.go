@@ -5,13 +5,16 @@ import (
 	"compress/gzip"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
 	"github.com/nomoyu/go-gin-framework/internal/logger"
+	"github.com/nomoyu/go-gin-framework/pkg/reqctx"
 	"github.com/nomoyu/go-gin-framework/pkg/trace"
 )
 
@@ -20,19 +23,53 @@ type bodyLogWriter struct {
 	body *bytes.Buffer
 }
 
+// maxLoggedResp 响应体只保留日志需要的部分，避免下载、SSE 等长响应占满内存
+const maxLoggedResp = 500
+
 func (w bodyLogWriter) Write(b []byte) (int, error) {
-	w.body.Write(b)
+	if room := maxLoggedResp + 1 - w.body.Len(); room > 0 {
+		w.body.Write(b[:min(len(b), room)])
+	}
 	return w.ResponseWriter.Write(b)
 }
 
+// Unwrap 供 http.ResponseController 访问底层连接（SSE 取消写超时等）
+func (w bodyLogWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+var (
+	omitMu       sync.RWMutex
+	omitPrefixes []string
+)
+
+// OmitBodies 路径以 prefix 开头的请求不记录请求体与响应体（配置中心等可能包含密钥的接口）
+func OmitBodies(prefixes ...string) {
+	omitMu.Lock()
+	defer omitMu.Unlock()
+	omitPrefixes = append(omitPrefixes, prefixes...)
+}
+
+func bodiesOmitted(path string) bool {
+	omitMu.RLock()
+	defer omitMu.RUnlock()
+	for _, p := range omitPrefixes {
+		if strings.HasPrefix(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
 func RequestLoggerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 
-		// 原始路径（不带 query）+ 带 query 的显示路径
+		// 原始路径（不带 query）+ 带 query 的显示路径；query 中的 token 脱敏后再记录
 		rawPath := c.Request.URL.Path
 		path := rawPath
 		if q := c.Request.URL.RawQuery; q != "" {
+			q = redactQuery(q)
 			path += "?" + q
 			rawPath += "?" + q
 		}
@@ -52,7 +89,11 @@ func RequestLoggerMiddleware() gin.HandlerFunc {
 		if route == "" {
 			route = "-"
 		}
-		reqBody, _ := readAndRestoreBody(c.Request, 2048) // 最多记录 2KB
+		omit := bodiesOmitted(c.Request.URL.Path)
+		reqBody := "[omitted]"
+		if !omit {
+			reqBody, _ = readAndRestoreBody(c.Request, 2048) // 最多记录 2KB
+		}
 		// ---- 请求开始（REQ）----
 		logger.
 			WithRouteColumn(rawPath).Info("<--",
@@ -73,8 +114,11 @@ func RequestLoggerMiddleware() gin.HandlerFunc {
 		status := c.Writer.Status()
 
 		resp := blw.body.String()
-		if len(resp) > 500 {
-			resp = resp[:500] + "...(truncated)"
+		if len(resp) > maxLoggedResp {
+			resp = resp[:maxLoggedResp] + "...(truncated)"
+		}
+		if omit {
+			resp = "[omitted]"
 		}
 
 		auth := c.GetHeader("Authorization")
@@ -90,6 +134,7 @@ func RequestLoggerMiddleware() gin.HandlerFunc {
 			//zap.String("route", route),
 			zap.Int("status", status),
 			zap.Duration("cost", lat),
+			versionField(c),
 			//zap.String("auth", auth),
 			// 如果不想打印响应体，删除下一行
 			zap.String("resp", resp),
@@ -97,6 +142,14 @@ func RequestLoggerMiddleware() gin.HandlerFunc {
 	}
 }
 
+// versionField 命中版本化路由时记录 API 版本
+func versionField(c *gin.Context) zap.Field {
+	if v := reqctx.FromGin(c).Version; v != "" {
+		return zap.String("version", v)
+	}
+	return zap.Skip()
+}
+
 func redact(s string) string {
 	if s == "" {
 		return ""
@@ -110,6 +163,26 @@ func redact(s string) string {
 	return s[:4] + "****" + s[len(s)-2:]
 }
 
+// secretQueryParams 可能携带凭据的 query 参数（WebSocket 握手的 access_token/token 等）
+var secretQueryParams = map[string]bool{"access_token": true, "token": true}
+
+// redactQuery 把 secretQueryParams 的值替换为 ****，其余参数与顺序保持不变
+func redactQuery(q string) string {
+	parts := strings.Split(q, "&")
+	changed := false
+	for i, part := range parts {
+		key, _, hasValue := strings.Cut(part, "=")
+		if name, err := url.QueryUnescape(key); err == nil && hasValue && secretQueryParams[strings.ToLower(name)] {
+			parts[i] = key + "=****"
+			changed = true
+		}
+	}
+	if !changed {
+		return q
+	}
+	return strings.Join(parts, "&")
+}
+
 // 读取并复位请求体；返回日志用的字符串
 func readAndRestoreBody(r *http.Request, max int) (string, error) {
 	if r.Body == nil {
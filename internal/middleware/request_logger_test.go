@@ -0,0 +1,20 @@
+package middleware
+
+import "testing"
+
+func TestRedactQuery(t *testing.T) {
+	cases := map[string]string{
+		"a=1&b=2":                       "a=1&b=2",
+		"access_token=abc.def&room=1":   "access_token=****&room=1",
+		"room=1&token=abc":              "room=1&token=****",
+		"Access%5FToken=abc":            "Access%5FToken=****",
+		"token":                         "token",
+		"tokens=1&x_token=2":            "tokens=1&x_token=2",
+		"token=a&token=b&access_token=": "token=****&token=****&access_token=****",
+	}
+	for in, want := range cases {
+		if got := redactQuery(in); got != want {
+			t.Errorf("redactQuery(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
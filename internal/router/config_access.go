@@ -0,0 +1,168 @@
+package router
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/internal/auth"
+	"github.com/nomoyu/go-gin-framework/internal/middleware"
+	"github.com/nomoyu/go-gin-framework/pkg/errorcode"
+	"github.com/nomoyu/go-gin-framework/pkg/response"
+)
+
+// ConfigAccess 配置中心页面与接口的访问控制
+type ConfigAccess struct {
+	// 专用管理员账号（HTTP Basic 认证），配置后不再使用 Strategy
+	Username string
+	Password string
+	// 应用的认证策略，认证信息的 roles 中需包含 Role
+	Strategy auth.AuthStrategy
+	Role     string
+	// 允许访问的地址（按直连地址判断，不信任 X-Forwarded-For），为空不限制
+	AllowIPs []netip.Prefix
+	// 只读：拒绝保存与回滚
+	ReadOnly bool
+}
+
+const (
+	csrfField  = "csrf_token"
+	csrfHeader = "X-CSRF-Token"
+	// csrfTTL 页面打开后多久内可以提交
+	csrfTTL = 12 * time.Hour
+)
+
+// configGuard allowIP 检查来源地址，handle 检查身份与 CSRF 令牌
+type configGuard struct {
+	access  ConfigAccess
+	csrfKey []byte
+}
+
+func newConfigGuard(access ConfigAccess) *configGuard {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return &configGuard{access: access, csrfKey: key}
+}
+
+func (g *configGuard) allowIP(c *gin.Context) {
+	if !middleware.IPAllowed(g.access.AllowIPs, c.RemoteIP()) {
+		deny(c, http.StatusForbidden, errorcode.Forbidden.WithMsg("来源地址不在配置中心的访问名单中"))
+		return
+	}
+	c.Next()
+}
+
+func (g *configGuard) handle(c *gin.Context) {
+	if !g.authenticate(c) {
+		return
+	}
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead && !g.checkCSRF(c) {
+		deny(c, http.StatusForbidden, errorcode.Forbidden.WithMsg("CSRF 令牌无效或已过期，请刷新页面后重试"))
+		return
+	}
+	c.Next()
+}
+
+// authenticate 认证通过时把认证信息写入上下文（AuthInfo），否则中止请求
+func (g *configGuard) authenticate(c *gin.Context) bool {
+	if g.access.Username != "" {
+		user, pass, ok := c.Request.BasicAuth()
+		if !ok || !equalSecret(user, g.access.Username) || !equalSecret(pass, g.access.Password) {
+			c.Header("WWW-Authenticate", `Basic realm="nomoyu config center", charset="UTF-8"`)
+			deny(c, http.StatusUnauthorized, errorcode.Unauthorized)
+			return false
+		}
+		c.Set("AuthInfo", map[string]interface{}{"username": user, "roles": []interface{}{g.access.Role}})
+		return true
+	}
+
+	info, err := g.access.Strategy.Authenticate(c)
+	if err != nil {
+		deny(c, http.StatusUnauthorized, errorcode.Unauthorized.WithMsg(err.Error()))
+		return false
+	}
+	c.Set("AuthInfo", info)
+	if !hasRole(info, g.access.Role) {
+		deny(c, http.StatusForbidden, errorcode.Forbidden.WithMsg("需要 "+g.access.Role+" 角色"))
+		return false
+	}
+	return true
+}
+
+// equalSecret 常量时间比较（先取摘要，避免泄露长度）
+func equalSecret(got, want string) bool {
+	a, b := sha256.Sum256([]byte(got)), sha256.Sum256([]byte(want))
+	return subtle.ConstantTimeCompare(a[:], b[:]) == 1
+}
+
+// hasRole 认证信息中的 roles 可以是字符串数组或逗号分隔的字符串
+func hasRole(info map[string]interface{}, role string) bool {
+	switch roles := info["roles"].(type) {
+	case []interface{}:
+		for _, r := range roles {
+			if fmt.Sprint(r) == role {
+				return true
+			}
+		}
+	case []string:
+		for _, r := range roles {
+			if r == role {
+				return true
+			}
+		}
+	case string:
+		for _, r := range strings.Split(roles, ",") {
+			if strings.TrimSpace(r) == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// csrfToken 生成绑定当前操作人的令牌：签发时间.HMAC(操作人, 签发时间)
+func (g *configGuard) csrfToken(c *gin.Context) string {
+	ts := strconv.FormatInt(time.Now().Unix(), 36)
+	return ts + "." + g.csrfMAC(actorOf(c), ts)
+}
+
+func (g *configGuard) csrfMAC(actor, ts string) string {
+	mac := hmac.New(sha256.New, g.csrfKey)
+	mac.Write([]byte(actor + "\n" + ts))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// checkCSRF 以 Bearer 令牌认证的请求不会被浏览器自动携带凭据，无需 CSRF 令牌
+func (g *configGuard) checkCSRF(c *gin.Context) bool {
+	if strings.HasPrefix(c.GetHeader("Authorization"), "Bearer ") {
+		return true
+	}
+	token := c.GetHeader(csrfHeader)
+	if token == "" {
+		token = c.PostForm(csrfField)
+	}
+	ts, mac, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	issued, err := strconv.ParseInt(ts, 36, 64)
+	if err != nil || time.Since(time.Unix(issued, 0)) > csrfTTL {
+		return false
+	}
+	return hmac.Equal([]byte(mac), []byte(g.csrfMAC(actorOf(c), ts)))
+}
+
+func deny(c *gin.Context, status int, ec errorcode.ErrorCode) {
+	c.AbortWithStatusJSON(status, response.Response{Code: ec.Code, Msg: ec.Msg})
+}
@@ -1,72 +1,393 @@
 package router
 
 import (
+	"context"
+	"crypto/subtle"
 	"embed"
+	"errors"
 	"fmt"
-	"github.com/gin-gonic/gin"
-	"github.com/nomoyu/go-gin-framework/pkg/config"
-	"gopkg.in/yaml.v3"
 	"html/template"
 	"net/http"
-	"os"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/internal/middleware"
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+	"github.com/nomoyu/go-gin-framework/pkg/configcenter"
+	"github.com/nomoyu/go-gin-framework/pkg/errorcode"
+	"github.com/nomoyu/go-gin-framework/pkg/logger"
+	"github.com/nomoyu/go-gin-framework/pkg/response"
 )
 
-func RegisterConfigRoutes(router *gin.Engine) {
-	router.GET("/config", renderConfigPage)
-	router.GET("/config/json", getConfigJSON)
-	router.POST("/config/save", saveConfig)
+// RegisterConfigRoutes 注册配置中心页面与 JSON 接口；接口的 app/env 参数为空时操作本应用的文档。
+// 全部接口只接受 access.AllowIPs 中的来源；页面与接口还需通过 access 的身份认证，
+// 远程拉取接口改用独立的 config.center.token
+func RegisterConfigRoutes(router *gin.Engine, center *configcenter.Center, access ConfigAccess) {
+	h := &configHandler{center: center, guard: newConfigGuard(access)}
+	// 提交的文档与拉取的原文可能包含密钥
+	middleware.OmitBodies("/config")
+	root := router.Group("/config", h.guard.allowIP)
+	root.GET("/remote/:app/:env", h.remoteDocument)
+
+	g := root.Group("", h.guard.handle)
+	g.GET("", h.renderConfigPage)
+	g.GET("/json", h.getConfigJSON)
+	g.GET("/document", h.getDocument)
+	g.GET("/versions", h.listVersions)
+	g.GET("/diff", h.getDiff)
+	g.GET("/audit", h.listAudit)
+	g.GET("/csrf", h.getCSRFToken)
+	g.POST("/save", h.writable, h.saveConfig)
+	g.POST("/rollback/:version", h.writable, h.rollback)
 }
 
-func saveConfig(c *gin.Context) {
-	yamlText := c.PostForm("yaml")
+type configHandler struct {
+	center *configcenter.Center
+	guard  *configGuard
+}
 
-	var temp config.AppConfig
-	if err := yaml.Unmarshal([]byte(yamlText), &temp); err != nil {
-		c.String(http.StatusBadRequest, fmt.Sprintf("❌ 配置文件格式错误：%v", err))
+// writable 只读模式下拒绝修改
+func (h *configHandler) writable(c *gin.Context) {
+	if h.guard.access.ReadOnly {
+		deny(c, http.StatusForbidden, errorcode.Forbidden.WithMsg("配置中心为只读模式"))
 		return
 	}
+	c.Next()
+}
+
+// getCSRFToken 使用 HTTP Basic 等浏览器会自动携带的凭据调用保存/回滚接口时，需在 X-CSRF-Token 头中带上该令牌
+func (h *configHandler) getCSRFToken(c *gin.Context) {
+	response.Success(c, gin.H{"token": h.guard.csrfToken(c), "header": csrfHeader})
+}
 
-	err := os.WriteFile("config.remote.yaml", []byte(yamlText), 0644)
+// maxRemoteWait 长轮询最长等待时间
+const maxRemoteWait = time.Minute
+
+// remoteDocument 远程配置客户端拉取文档（未脱敏），需携带 config.center.token：
+// version 为客户端当前版本，wait 为长轮询等待时间；没有更新的版本时返回 304，文档不存在时返回 404
+func (h *configHandler) remoteDocument(c *gin.Context) {
+	token := config.Current().Config.Center.Token
+	if token == "" {
+		c.String(http.StatusForbidden, "config.center.token is not configured")
+		return
+	}
+	got := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+		c.String(http.StatusUnauthorized, "invalid token")
+		return
+	}
+
+	app, env := c.Param("app"), c.Param("env")
+	after, err := strconv.Atoi(c.DefaultQuery("version", "0"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "invalid version")
+		return
+	}
+	wait, err := time.ParseDuration(c.DefaultQuery("wait", "0s"))
 	if err != nil {
-		c.String(http.StatusInternalServerError, fmt.Sprintf("保存失败: %v", err))
+		c.String(http.StatusBadRequest, "invalid wait")
 		return
 	}
+	wait = min(wait, maxRemoteWait)
 
-	c.String(http.StatusOK, "✅ 配置已保存成功！")
+	if wait > 0 {
+		// 长轮询可能超过业务端口的 WriteTimeout
+		_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(wait + 10*time.Second))
+		ctx, cancel := context.WithTimeout(c.Request.Context(), wait)
+		defer cancel()
+		if _, err := h.center.Wait(ctx, app, env, after); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+				c.Status(http.StatusNotModified)
+				return
+			}
+			h.remoteFail(c, err)
+			return
+		}
+	}
+
+	v, data, err := h.center.Raw(app, env, 0)
+	if err != nil {
+		h.remoteFail(c, err)
+		return
+	}
+	if v.Version <= after {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Header(configcenter.VersionHeader, strconv.Itoa(v.Version))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/yaml; charset=utf-8", data)
 }
-func getConfigJSON(context *gin.Context) {
 
+func (h *configHandler) remoteFail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, configcenter.ErrNotFound):
+		c.String(http.StatusNotFound, "no document")
+	case errors.Is(err, configcenter.ErrBadTarget):
+		c.String(http.StatusBadRequest, err.Error())
+	default:
+		logger.Errorf("config center: %v", err)
+		c.String(http.StatusInternalServerError, "internal error")
+	}
 }
 
-//go:embed template/*
-var tmplFS embed.FS
+// getConfigJSON 当前生效的完整配置（脱敏），键名与配置文件一致
+func (h *configHandler) getConfigJSON(c *gin.Context) {
+	v, _, err := h.center.Document("", "", 0)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	response.Success(c, gin.H{
+		"env":     config.Env(),
+		"files":   config.Files(),
+		"version": v.Version,
+		"config":  config.Effective(),
+	})
+}
+
+func (h *configHandler) getDocument(c *gin.Context) {
+	version, ok := intQuery(c, "version", 0)
+	if !ok {
+		return
+	}
+	v, data, err := h.center.Document(c.Query("app"), c.Query("env"), version)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	response.Success(c, gin.H{"version": v, "content": string(data)})
+}
 
-func renderConfigPage(c *gin.Context) {
-	// 获取缓存配置或默认配置
-	var currentConfig *config.AppConfig
-	currentConfig = config.Conf
+func (h *configHandler) listVersions(c *gin.Context) {
+	app, env := h.target(c.Query("app"), c.Query("env"))
+	list, err := h.center.Store().Versions(app, env)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	slices.Reverse(list)
+	response.Success(c, list)
+}
 
-	// 序列化为 YAML 字符串（用于 textarea 显示）
-	yamlBytes, err := yaml.Marshal(currentConfig)
+// getDiff from 必填，to 为空时与最新版本比较
+func (h *configHandler) getDiff(c *gin.Context) {
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		response.Fail(c, errorcode.InvalidParams.Code, "请求参数不合法: from")
+		return
+	}
+	to, ok := intQuery(c, "to", 0)
+	if !ok {
+		return
+	}
+	lines, err := h.center.Diff(c.Query("app"), c.Query("env"), from, to)
 	if err != nil {
-		c.String(http.StatusInternalServerError, "配置转换失败: %v", err)
+		h.fail(c, err)
 		return
 	}
+	response.Success(c, lines)
+}
 
-	// 加载模板
-	tmplBytes, err := tmplFS.ReadFile("template/config.tmpl")
+func (h *configHandler) listAudit(c *gin.Context) {
+	limit, ok := intQuery(c, "limit", 50)
+	if !ok {
+		return
+	}
+	list, err := h.center.Store().Audit(c.Query("app"), c.Query("env"), limit)
 	if err != nil {
-		c.String(http.StatusInternalServerError, "模板加载失败: %v", err)
+		h.fail(c, err)
 		return
 	}
+	response.Success(c, list)
+}
 
-	tmpl, err := template.New("config").Parse(string(tmplBytes))
+// saveConfig 支持表单（config、comment，保存后跳回页面）与 JSON（{"config": "...", "comment": "..."}）
+func (h *configHandler) saveConfig(c *gin.Context) {
+	var req struct {
+		App     string `json:"app" form:"app"`
+		Env     string `json:"env" form:"env"`
+		Config  string `json:"config" form:"config"`
+		Comment string `json:"comment" form:"comment"`
+	}
+	if err := c.ShouldBind(&req); err != nil {
+		response.Fail(c, errorcode.InvalidParams.Code, "请求参数不合法: "+err.Error())
+		return
+	}
+	v, err := h.center.Save(configcenter.Change{
+		App:     req.App,
+		Env:     req.Env,
+		Content: []byte(req.Config),
+		Comment: req.Comment,
+		Actor:   actorOf(c),
+		IP:      c.ClientIP(),
+	})
+	h.reply(c, v, err, "保存")
+}
+
+func (h *configHandler) rollback(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
 	if err != nil {
-		c.String(http.StatusInternalServerError, "模板解析失败: %v", err)
+		response.Fail(c, errorcode.InvalidParams.Code, "请求参数不合法: version")
 		return
 	}
+	v, err := h.center.Rollback(c.Query("app"), c.Query("env"), version, actorOf(c), c.ClientIP())
+	h.reply(c, v, err, fmt.Sprintf("回滚到 v%d", version))
+}
+
+// reply 表单提交重定向回页面并带上提示，JSON 请求返回统一响应
+func (h *configHandler) reply(c *gin.Context, v configcenter.Version, err error, action string) {
+	if err == nil {
+		logger.Warnf("config center: %s by %s, now v%d", action, actorOf(c), v.Version)
+	}
+	if c.ContentType() == gin.MIMEJSON {
+		if err != nil && !errors.Is(err, configcenter.ErrNoChange) {
+			h.fail(c, err)
+			return
+		}
+		response.Success(c, v)
+		return
+	}
+
+	q := url.Values{}
+	switch {
+	case errors.Is(err, configcenter.ErrNoChange):
+		q.Set("msg", "配置没有变化")
+	case err != nil:
+		q.Set("err", err.Error())
+	default:
+		q.Set("msg", fmt.Sprintf("✅ %s成功，当前版本 v%d", action, v.Version))
+	}
+	c.Redirect(http.StatusSeeOther, "/config?"+q.Encode())
+}
+
+// intQuery 可选的非负整数查询参数，未传时为 def；不合法时返回 InvalidParams
+func intQuery(c *gin.Context, name string, def int) (int, bool) {
+	s := c.Query(name)
+	if s == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		response.Fail(c, errorcode.InvalidParams.Code, "请求参数不合法: "+name)
+		return 0, false
+	}
+	return n, true
+}
+
+func (h *configHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, configcenter.ErrInvalid), errors.Is(err, configcenter.ErrBadTarget):
+		response.Fail(c, errorcode.InvalidParams.Code, err.Error())
+	case errors.Is(err, configcenter.ErrNotFound):
+		response.Fail(c, errorcode.InvalidParams.Code, "版本不存在")
+	default:
+		logger.Errorf("config center: %v", err)
+		response.FailWithCode(c, errorcode.ServerError)
+	}
+}
+
+func (h *configHandler) target(app, env string) (string, string) {
+	ownApp, ownEnv := h.center.Target()
+	if app == "" {
+		app = ownApp
+	}
+	if env == "" {
+		env = ownEnv
+	}
+	return app, env
+}
+
+// actorOf 审计记录中的操作人：认证信息中的用户名，未认证时为 anonymous
+func actorOf(c *gin.Context) string {
+	if v, ok := c.Get("AuthInfo"); ok {
+		if info, ok := v.(map[string]interface{}); ok {
+			for _, key := range []string{"username", "name", "sub", "user_id", "uid"} {
+				if s := fmt.Sprint(info[key]); info[key] != nil && s != "" {
+					return s
+				}
+			}
+		}
+	}
+	return "anonymous"
+}
+
+//go:embed template/*
+var tmplFS embed.FS
+
+var configPage = template.Must(template.New("config.tmpl").Funcs(template.FuncMap{
+	"prev": func(v int) int { return v - 1 },
+}).ParseFS(tmplFS, "template/config.tmpl"))
+
+type configPageData struct {
+	App      string
+	Env      string
+	Files    []string
+	Current  configcenter.Version
+	Content  string
+	Versions []configcenter.Version
+	Audit    []configcenter.AuditEntry
+	Diff     []configcenter.DiffLine
+	DiffFrom int
+	DiffTo   int
+	Message  string
+	Error    string
+	CSRF     string
+	ReadOnly bool
+}
+
+// renderConfigPage 编辑本应用在配置中心的文档（叠加在配置文件之上），附版本历史、差异与审计记录
+func (h *configHandler) renderConfigPage(c *gin.Context) {
+	app, env := h.center.Target()
+	data := configPageData{
+		App:      app,
+		Env:      env,
+		Files:    config.Files(),
+		Message:  c.Query("msg"),
+		Error:    c.Query("err"),
+		CSRF:     h.guard.csrfToken(c),
+		ReadOnly: h.guard.access.ReadOnly,
+	}
+
+	var err error
+	var content []byte
+	if data.Current, content, err = h.center.Document("", "", 0); err != nil {
+		h.fail(c, err)
+		return
+	}
+	data.Content = string(content)
+	if data.Versions, err = h.center.Store().Versions(app, env); err != nil {
+		h.fail(c, err)
+		return
+	}
+	slices.Reverse(data.Versions)
+	if data.Audit, err = h.center.Store().Audit(app, env, 20); err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	if from, err := strconv.Atoi(c.Query("from")); err == nil {
+		data.DiffFrom = from
+		if to := c.Query("to"); to != "" {
+			if data.DiffTo, err = strconv.Atoi(to); err != nil && data.Error == "" {
+				data.Error = "请求参数不合法: to"
+			}
+		}
+		if data.DiffTo <= 0 {
+			data.DiffTo = data.Current.Version
+		}
+		if data.Diff, err = h.center.Diff("", "", from, data.DiffTo); err != nil && data.Error == "" {
+			data.Error = strings.TrimPrefix(err.Error(), "configcenter: ")
+		}
+	}
 
-	// 渲染模板，传入 YAML 字符串
 	c.Header("Content-Type", "text/html; charset=utf-8")
-	tmpl.Execute(c.Writer, string(yamlBytes))
+	c.Header("Cache-Control", "no-store")
+	if err := configPage.Execute(c.Writer, data); err != nil {
+		logger.Errorf("config center: render page: %v", err)
+	}
 }
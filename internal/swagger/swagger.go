@@ -22,3 +22,12 @@ func New(route string) *SwaggerModule {
 func (s *SwaggerModule) Register(r *gin.Engine) {
 	r.GET(s.route, ginSwagger.WrapHandler(swaggerFiles.Handler))
 }
+
+func (s *SwaggerModule) Name() string {
+	return "swagger"
+}
+
+// AdminOnly swagger 属于运维接口，开启管理端口时只在管理端提供
+func (s *SwaggerModule) AdminOnly() bool {
+	return true
+}
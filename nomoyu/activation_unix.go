@@ -0,0 +1,58 @@
+//go:build !windows
+
+package nomoyu
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/nomoyu/go-gin-framework/pkg/logger"
+)
+
+// systemd 传入的第一个 fd
+const listenFDsStart = 3
+
+// systemdListener systemd socket 激活（LISTEN_PID / LISTEN_FDS / LISTEN_FDNAMES）：
+// 优先按 FileDescriptorName 匹配 main / admin，未命名时第 1 个为业务端口、第 2 个为管理端口
+func systemdListener(name string) (net.Listener, bool, error) {
+	if os.Getenv("LISTEN_PID") != strconv.Itoa(os.Getpid()) {
+		return nil, false, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, false, nil
+	}
+
+	idx := -1
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	for i := 0; i < n && i < len(names); i++ {
+		if names[i] == name {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		switch name {
+		case listenerMain:
+			idx = 0
+		case listenerAdmin:
+			idx = 1
+		}
+	}
+	if idx < 0 || idx >= n {
+		return nil, false, nil
+	}
+
+	fd := listenFDsStart + idx
+	f := os.NewFile(uintptr(fd), name)
+	defer f.Close()
+	ln, err := net.FileListener(f)
+	if err != nil {
+		return nil, false, fmt.Errorf("systemd socket activation (fd=%d): %w", fd, err)
+	}
+	logger.Infof("using systemd activated socket for %s listener (fd=%d)", name, fd)
+	return ln, true, nil
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package nomoyu
+
+import "net"
+
+func systemdListener(name string) (net.Listener, bool, error) {
+	return nil, false, nil
+}
@@ -0,0 +1,150 @@
+package nomoyu
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"net/netip"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/internal/middleware"
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+	"github.com/nomoyu/go-gin-framework/pkg/errorcode"
+	"github.com/nomoyu/go-gin-framework/pkg/logger"
+	"github.com/nomoyu/go-gin-framework/pkg/response"
+)
+
+// AdminModule 可选：运维类模块（如 swagger），Register 时传入的是管理端 engine；
+// 未开启管理端口时退化为业务 engine
+type AdminModule interface {
+	AdminOnly() bool
+}
+
+type AdminOption struct {
+	Addr     string
+	AllowIPs []string // 允许访问的 IP 或网段，为空时只允许本机
+	Public   bool     // 允许监听非回环地址
+	FromUser bool
+}
+
+// WithAdminServer 在独立端口上提供运维接口（优先级高于配置 admin.*）
+func (a *App) WithAdminServer(addr string, opts ...func(*AdminOption)) *App {
+	opt := AdminOption{Addr: addr}
+	for _, f := range opts {
+		f(&opt)
+	}
+	opt.FromUser = true
+	a.adminOption = &opt
+	return a
+}
+
+// initAdminIfPresent 开启管理端口时创建独立的 gin engine；pprof、日志级别等接口没有认证，
+// 默认只允许监听回环地址并只接受本机请求
+func initAdminIfPresent(a *App) error {
+	if a.adminOption == nil || !a.adminOption.FromUser {
+		conf := config.Current().Admin
+		if conf.Addr == "" {
+			return nil
+		}
+		a.adminOption = &AdminOption{Addr: conf.Addr, AllowIPs: conf.AllowIPs, Public: conf.Public, FromUser: false}
+	}
+	opt := a.adminOption
+
+	if !opt.Public && !isLoopbackAddr(opt.Addr) {
+		return fmt.Errorf("admin addr %s is not a loopback address, set admin.public (AdminOption.Public) to listen on it", opt.Addr)
+	}
+	allow := middleware.LoopbackOnly
+	if len(opt.AllowIPs) > 0 {
+		var err error
+		if allow, err = middleware.ParseAllowIPs(opt.AllowIPs); err != nil {
+			return fmt.Errorf("admin allow_ips: %w", err)
+		}
+	}
+
+	a.adminEngine = gin.New()
+	a.adminEngine.Use(
+		middleware.TraceID(),
+		middleware.RequestContext(),
+		middleware.RecoveryMiddleware(),
+		middleware.RequestLoggerMiddleware(),
+		middleware.AllowIPs(allow, "来源地址不在管理端口的访问名单中"),
+	)
+	a.adminEngine.NoRoute(func(c *gin.Context) {
+		response.NotFound(c, "无法找到您请求的页面")
+	})
+
+	a.adminEngine.GET(livenessPath, a.liveness)
+	a.adminEngine.GET(readinessPath, a.readiness)
+	registerPprofRoutes(a.adminEngine)
+	registerLogLevelRoutes(a.adminEngine)
+	a.registerRoutesRoute(a.adminEngine)
+	return nil
+}
+
+// isLoopbackAddr host 为 localhost 或回环 IP；host 为空（监听所有地址）时返回 false
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip, err := netip.ParseAddr(host)
+	return err == nil && ip.IsLoopback()
+}
+
+// opsEngine 运维接口挂载的 engine：开启管理端口时为管理端 engine，否则为业务 engine
+func (a *App) opsEngine() *gin.Engine {
+	if a.adminEngine != nil {
+		return a.adminEngine
+	}
+	return a.engine
+}
+
+// newAdminServer 管理端不设 WriteTimeout，避免 pprof profile/trace 被截断
+func (a *App) newAdminServer() *http.Server {
+	return &http.Server{
+		Addr:              a.adminOption.Addr,
+		Handler:           a.adminEngine,
+		ReadHeaderTimeout: 5 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+}
+
+func registerPprofRoutes(r *gin.Engine) {
+	g := r.Group("/debug/pprof")
+	g.GET("/", gin.WrapF(pprof.Index))
+	g.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	g.GET("/profile", gin.WrapF(pprof.Profile))
+	g.GET("/symbol", gin.WrapF(pprof.Symbol))
+	g.POST("/symbol", gin.WrapF(pprof.Symbol))
+	g.GET("/trace", gin.WrapF(pprof.Trace))
+	g.GET("/:name", func(c *gin.Context) {
+		pprof.Handler(c.Param("name")).ServeHTTP(c.Writer, c.Request)
+	})
+}
+
+// registerLogLevelRoutes 运行时查看/调整日志级别
+func registerLogLevelRoutes(r *gin.Engine) {
+	r.GET("/log/level", func(c *gin.Context) {
+		response.Success(c, gin.H{"level": logger.GetLevel()})
+	})
+	r.PUT("/log/level", func(c *gin.Context) {
+		var req struct {
+			Level string `json:"level" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Fail(c, errorcode.InvalidParams.Code, "请求参数不合法: "+err.Error())
+			return
+		}
+		if err := logger.SetLevel(req.Level); err != nil {
+			response.Fail(c, errorcode.InvalidParams.Code, "不支持的日志级别: "+req.Level)
+			return
+		}
+		logger.Warnf("log level changed to %s", logger.GetLevel())
+		response.Success(c, gin.H{"level": logger.GetLevel()})
+	})
+}
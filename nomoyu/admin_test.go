@@ -0,0 +1,115 @@
+package nomoyu_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/nomoyu/go-gin-framework/nomoyu"
+	"github.com/nomoyu/go-gin-framework/pkg/errorcode"
+	"github.com/nomoyu/go-gin-framework/pkg/logger"
+	"github.com/nomoyu/go-gin-framework/pkg/response"
+)
+
+// doJSON 发送请求并解析通用响应
+func doJSON(t *testing.T, method, url, body string) (int, response.Response) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var out response.Response
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode %s %s: %v", method, url, err)
+	}
+	return resp.StatusCode, out
+}
+
+func TestAdminServerRoutes(t *testing.T) {
+	loadTestConfig(t)
+	prevLevel := logger.GetLevel()
+	t.Cleanup(func() { logger.SetLevel(prevLevel) })
+
+	addr, adminAddr := freeAddr(t), freeAddr(t)
+	startApp(t, nomoyu.New().WithAdminServer(adminAddr), addr)
+	admin := "http://" + adminAddr
+
+	resp, err := http.Get(admin + "/debug/pprof/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("pprof index: %d", resp.StatusCode)
+	}
+	// 运维接口不挂在业务端口上
+	resp, err = http.Get("http://" + addr + "/debug/pprof/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("pprof on main port: %d", resp.StatusCode)
+	}
+
+	// 日志级别：修改后再次查询得到新级别，非法级别被拒绝且不生效
+	code, out := doJSON(t, http.MethodPut, admin+"/log/level", `{"level":"debug"}`)
+	if code != http.StatusOK || out.Code != response.CodeSuccess {
+		t.Fatalf("PUT /log/level: %d %+v", code, out)
+	}
+	_, out = doJSON(t, http.MethodGet, admin+"/log/level", "")
+	if level := out.Data.(map[string]any)["level"]; level != "debug" || logger.GetLevel() != "debug" {
+		t.Fatalf("GET /log/level = %v, logger level %s", level, logger.GetLevel())
+	}
+	_, out = doJSON(t, http.MethodPut, admin+"/log/level", `{"level":"loud"}`)
+	if out.Code != errorcode.InvalidParams.Code || logger.GetLevel() != "debug" {
+		t.Fatalf("invalid level: %+v, logger level %s", out, logger.GetLevel())
+	}
+}
+
+func TestAdminServerAllowIPs(t *testing.T) {
+	loadTestConfig(t)
+	addr, adminAddr := freeAddr(t), freeAddr(t)
+	startApp(t, nomoyu.New().WithAdminServer(adminAddr, func(o *nomoyu.AdminOption) {
+		o.AllowIPs = []string{"10.0.0.0/8"}
+	}), addr)
+
+	req, _ := http.NewRequest(http.MethodPut, "http://"+adminAddr+"/log/level", bytes.NewBufferString(`{"level":"debug"}`))
+	req.Header.Set("X-Forwarded-For", "10.0.0.1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("request from outside allow_ips: %d", resp.StatusCode)
+	}
+}
+
+func TestAdminServerRefusesPublicAddr(t *testing.T) {
+	loadTestConfig(t)
+	if err := nomoyu.New().WithAdminServer(":0").Build(); err == nil || !strings.Contains(err.Error(), "loopback") {
+		t.Fatalf("Build with admin on all interfaces: %v", err)
+	}
+
+	// 显式开启 public 后可以监听，来源名单默认仍只允许本机
+	addr, adminAddr := freeAddr(t), freeAddr(t)
+	port := adminAddr[strings.LastIndex(adminAddr, ":"):]
+	startApp(t, nomoyu.New().WithAdminServer(port, func(o *nomoyu.AdminOption) { o.Public = true }), addr)
+	resp, err := http.Get("http://" + adminAddr + "/log/level")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("local request to public admin server: %d", resp.StatusCode)
+	}
+}
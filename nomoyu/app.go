@@ -2,68 +2,227 @@ package nomoyu
 
 import (
 	"context"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/nomoyu/go-gin-framework/internal/middleware"
 	"github.com/nomoyu/go-gin-framework/pkg/config"
-	"github.com/nomoyu/go-gin-framework/pkg/response"
+	"github.com/nomoyu/go-gin-framework/pkg/configcenter"
+	"github.com/nomoyu/go-gin-framework/pkg/sse"
+	"github.com/nomoyu/go-gin-framework/pkg/ws"
+	"net"
 	"net/http"
+	"sync"
 	"time"
 )
 
 type App struct {
-	engine          *gin.Engine
-	modules         []Module
-	routes          []RouteGroup
-	logOption       *LogOption
-	scheduler       *SchedulerOption
-	serverOption    *ServerOption
-	authOption      *AuthOption
-	dbOption        *DBOption
-	httpServer      *http.Server
-	shutdownTimeout time.Duration
-	redisOption     *RedisOption
-	shutting        int32
-	shutdownHooks   []func(ctx context.Context) error
-	corsOption      *CORSOption
+	engine             *gin.Engine
+	modules            []Module
+	routes             []RouteGroup
+	logOption          *LogOption
+	scheduler          *SchedulerOption
+	serverOption       *ServerOption
+	authOption         *AuthOption
+	globalAuth         bool // WithAuth 已在业务 engine 上全局挂载认证
+	dbOption           *DBOption
+	httpServer         *http.Server
+	shutdownTimeout    time.Duration
+	redisOption        *RedisOption
+	shutting           int32
+	shutdownHooks      []func(ctx context.Context) error
+	stop               chan struct{} // Shutdown 关闭后 Run 停止等待信号
+	serving            int32         // Run 已开始启动服务
+	served             chan struct{} // Run 停机完成后关闭
+	stopOnce           sync.Once
+	shutdownOnce       sync.Once
+	corsOption         *CORSOption
+	rateLimitOption    *RateLimitOption
+	swaggerOption      *SwaggerOption
+	openapiOption      *OpenAPIOption
+	versioningOption   *VersioningOption
+	versionRouter      *versionRouter
+	statics            []staticMount
+	apiPrefixes        []string
+	wsOption           *WebSocketOption
+	wsHub              *ws.Hub
+	sseOption          *SSEOption
+	sseBroker          *sse.Broker
+	healthCheckers     []HealthChecker
+	drainDelay         time.Duration
+	draining           int32
+	tlsOption          *TLSOption
+	h2c                bool
+	adminOption        *AdminOption
+	adminEngine        *gin.Engine
+	adminServer        *http.Server
+	gracefulRestart    bool
+	listeners          []namedListener
+	connMu             sync.Mutex
+	pendingConns       map[net.Conn]struct{} // 已接受但还没读到请求的连接
+	routeMetas         map[*gin.Engine]map[string]routeMeta
+	configWatch        bool
+	configCenterOption *ConfigCenterOption
+	configCenter       *configcenter.Center
+	remoteConfigOption *RemoteConfigOption
+	remoteConfig       *configcenter.Client
+	built              bool
 }
 
+// New 创建应用（构建阶段），只记录 With* 选项，所有子系统在 Build/Run 中按顺序初始化
+func New() *App {
+	return &App{
+		engine:  gin.New(),
+		modules: []Module{},
+		routes:  []RouteGroup{},
+		stop:    make(chan struct{}),
+		served:  make(chan struct{}),
+	}
+}
+
+// Start 兼容旧入口，等价于 New()
 func Start() *App {
-	config.InitConfig()
+	return New()
+}
+
+func (a *App) WithModule(m Module) *App {
+	a.modules = append(a.modules, m)
+	return a
+}
+
+func (a *App) WithRoute(groups ...RouteGroup) *App {
+	a.routes = append(a.routes, groups...)
+	return a
+}
+
+// Engine 返回业务 gin.Engine（Build 之后才包含完整的中间件与路由）
+func (a *App) Engine() *gin.Engine {
+	return a.engine
+}
+
+// Handler 返回业务端实际对外服务的 http.Handler（按请求头/Accept 声明版本时包含路径改写）
+func (a *App) Handler() http.Handler {
+	if a.versionRouter != nil {
+		return a.versionRouter.wrap(a.engine)
+	}
+	return a.engine
+}
+
+// Build 按固定顺序初始化所有子系统并注册路由（幂等），Run 会自动调用
+//
+// 顺序：配置 -> 配置中心文档/远程配置 -> 日志 -> 配置热更新 -> 模块 Init(按依赖排序，内置 db/redis 在前) -> 全局中间件(CORS/限流/trace/recover/日志/关机防护)
+// -> 健康检查 -> 管理端 -> 认证 -> 配置中心页面 -> 全局认证(WithAuth) -> 模块 Register -> 路由分组 -> 打印路由表(dev)；模块 Start 在 Run 中监听前执行
+//
+// 任一步骤失败时倒序执行本次 Build 登记的停机钩子：取消配置订阅与远程配置轮询、停止已初始化的模块
+func (a *App) Build() (err error) {
+	if a.built {
+		return nil
+	}
 
-	app := &App{
-		engine:    gin.New(),
-		modules:   []Module{},
-		routes:    []RouteGroup{},
-		logOption: nil,
+	if config.Current() == nil {
+		if err := config.Load(); err != nil {
+			return fmt.Errorf("nomoyu: init config: %w", err)
+		}
 	}
 	printBanner()
-	initLogFromConfigIfPresent(app)
-	initRemoteConfigIfPresent(app)
-	initSwaggerFromConfigIfPresent(app)
-	initDBIfPresent(app)
-	initRedisFromConfigIfPresent(app)
-	initCORS(app)
-
-	app.engine.Use(
+
+	// 远程配置轮询等后台任务以 ctx 为父 context，Build 失败时一并取消
+	ctx, cancel := context.WithCancel(context.Background())
+	hooks := len(a.shutdownHooks)
+	defer func() {
+		if err != nil {
+			cancel()
+			stopCtx, stopCancel := context.WithTimeout(context.Background(), *resolveServerOption(a).ShutdownTimeout)
+			defer stopCancel()
+			a.runShutdownHooks(stopCtx, hooks)
+		}
+	}()
+
+	if err := initConfigCenterIfPresent(a); err != nil {
+		return fmt.Errorf("nomoyu: init config center: %w", err)
+	}
+	initRemoteConfigIfPresent(ctx, a)
+	initLogFromConfigIfPresent(a)
+	initConfigWatchIfPresent(a)
+	startRemoteConfig(ctx, a)
+	if err := a.useBuiltinModules(); err != nil {
+		return fmt.Errorf("nomoyu: %w", err)
+	}
+	if err := a.initModules(ctx); err != nil {
+		return fmt.Errorf("nomoyu: %w", err)
+	}
+	a.collectHealthCheckers()
+
+	initCORS(a)
+	initRateLimit(a)
+	a.engine.Use(
 		middleware.TraceID(),
 		middleware.RequestContext(),
 		middleware.RecoveryMiddleware(),
 		middleware.RequestLoggerMiddleware(),
+		a.shutdownGuard(),
 	)
-	app.engine.NoRoute(func(c *gin.Context) {
-		response.NotFound(c, "无法找到您请求的页面")
-	})
-	initAuthIfConfigured(app)
+	a.engine.NoRoute(a.noRoute)
+	a.registerHealthRoutes()
+	if err := initAdminIfPresent(a); err != nil {
+		return fmt.Errorf("nomoyu: init admin server: %w", err)
+	}
+	if a.adminEngine == nil && isDevEnv() {
+		a.registerRoutesRoute(a.engine)
+	}
 
-	return app
-}
+	if err := initAuthIfConfigured(a); err != nil {
+		return fmt.Errorf("nomoyu: init auth: %w", err)
+	}
+	if err := registerConfigCenterRoutes(a); err != nil {
+		return fmt.Errorf("nomoyu: init config center: %w", err)
+	}
+	useGlobalAuth(a)
 
-func (a *App) WithModule(m Module) *App {
-	a.modules = append(a.modules, m)
-	return a
+	// 注册模块（已按依赖排序），运维类模块挂到管理端
+	for _, m := range a.modules {
+		engine := a.engine
+		if am, ok := m.(AdminModule); ok && am.AdminOnly() {
+			engine = a.opsEngine()
+		}
+		a.trackRoutes(engine, routeMeta{module: ModuleName(m)}, func() {
+			m.Register(engine)
+		})
+	}
+
+	// 注册路由分组
+	if err := initVersioning(a); err != nil {
+		return fmt.Errorf("nomoyu: init versioning: %w", err)
+	}
+	for _, group := range a.routes {
+		a.registerGroup(group)
+	}
+	initStatic(a)
+	a.printRoutes()
+
+	a.built = true
+	return nil
 }
 
-func (a *App) WithRoute(groups ...RouteGroup) *App {
-	a.routes = append(a.routes, groups...)
-	return a
+// useBuiltinModules 把 With*/配置开启的内置子系统转换为模块：
+// db、redis 排在用户模块之前，swagger、openapi、scheduler 排在之后
+func (a *App) useBuiltinModules() error {
+	var head []Module
+	dbm, err := newDBModuleIfPresent(a)
+	if err != nil {
+		return fmt.Errorf("init database: %w", err)
+	}
+	if dbm != nil {
+		head = append(head, dbm)
+	}
+	if rm := newRedisModuleIfPresent(a); rm != nil {
+		head = append(head, rm)
+	}
+	a.modules = append(head, a.modules...)
+
+	initSwaggerFromConfigIfPresent(a)
+	initOpenAPIFromConfigIfPresent(a)
+	if a.scheduler != nil {
+		a.modules = append(a.modules, &schedulerModule{opt: a.scheduler})
+	}
+	return nil
 }
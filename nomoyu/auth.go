@@ -2,25 +2,22 @@ package nomoyu
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
 	"github.com/nomoyu/go-gin-framework/internal/auth"
 	"github.com/nomoyu/go-gin-framework/internal/middleware"
 	"github.com/nomoyu/go-gin-framework/pkg/config"
 	"github.com/nomoyu/go-gin-framework/pkg/logger"
 )
 
-type AuthConfig struct {
-	Enabled bool   `mapstructure:"enabled"`
-	Mode    string `mapstructure:"mode"`
-	JWT     struct {
-		Secret string `mapstructure:"secret"`
-	} `mapstructure:"jwt"`
-}
-
 type AuthOption struct {
 	Strategy auth.AuthStrategy
 	FromUser bool
 }
 
+// WithAuth 使用自定义认证策略，保护之后注册的所有业务路由（健康检查除外，配置中心有自己的访问控制）；
+// 通过配置 auth.enabled 开启的认证只作用于声明了 RequireAuth/AuthRequired 的路由
 func (a *App) WithAuth(strategy auth.AuthStrategy) *App {
 	a.authOption = &AuthOption{
 		Strategy: strategy,
@@ -29,24 +26,43 @@ func (a *App) WithAuth(strategy auth.AuthStrategy) *App {
 	return a
 }
 
-func initAuthIfConfigured(app *App) {
-	if app.authOption != nil {
-		app.engine.Use(middleware.AuthMiddleware(app.authOption.Strategy))
-		return
+// initAuthIfConfigured 确定认证策略（WithAuth 优先，其次配置文件），
+// 策略会挂载到所有声明了 RequireAuth 的路由分组上；WithAuth 的策略另由 useGlobalAuth 挂到整个业务 engine
+func initAuthIfConfigured(app *App) error {
+	if app.authOption != nil && app.authOption.FromUser {
+		return nil
 	}
 
-	conf := config.Conf.Auth
-	if conf.Enabled {
-		switch conf.Mode {
-		case "jwt":
-			a := &auth.JWTStrategy{Secret: conf.JWT.Secret}
-			app.authOption = &AuthOption{
-				Strategy: a,
-				FromUser: true,
-			}
-		default:
-			fmt.Println("not support auth mode:", conf.Mode)
+	conf := config.Current().Auth
+	if !conf.Enabled {
+		return nil
+	}
+	switch conf.Mode {
+	case "jwt":
+		app.authOption = &AuthOption{
+			Strategy: &auth.JWTStrategy{Secret: conf.JWT.Secret},
+			FromUser: false,
 		}
-		logger.Info("init nomoyu auth success...")
+	default:
+		return fmt.Errorf("not support auth mode: %s", conf.Mode)
 	}
+	logger.Info("init nomoyu auth success...")
+	return nil
+}
+
+// useGlobalAuth WithAuth 时在业务 engine 上全局挂载认证，之后注册的路由（模块、路由分组、静态文件）都需要认证；
+// WebSocket 握手先把 query/子协议中的 token 转成 Authorization 头
+func useGlobalAuth(app *App) {
+	if app.authOption == nil || !app.authOption.FromUser {
+		return
+	}
+	authn := middleware.AuthMiddleware(app.authOption.Strategy)
+	app.engine.Use(func(c *gin.Context) {
+		if strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+			copyWSToken(c)
+		}
+		authn(c)
+	})
+	app.globalAuth = true
+	logger.Info("init nomoyu auth success (WithAuth, all routes)...")
 }
@@ -0,0 +1,49 @@
+package nomoyu_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/internal/auth"
+	"github.com/nomoyu/go-gin-framework/nomoyu"
+	"github.com/nomoyu/go-gin-framework/nomoyu/nomoyutest"
+	"github.com/nomoyu/go-gin-framework/pkg/response"
+)
+
+func TestWithAuthProtectsAllRoutes(t *testing.T) {
+	h := nomoyutest.New(t, nomoyutest.WithApp(func(a *nomoyu.App) {
+		a.WithAuth(&auth.JWTStrategy{Secret: nomoyutest.DefaultJWTSecret})
+		a.WithRoute(nomoyu.NewGroup("/api").GET("/ping", func(c *gin.Context) { response.Success(c, "pong") }))
+		a.WithRoute(nomoyu.NewGroup("/sec").RequireAuth().GET("/me", func(c *gin.Context) { response.Success(c, "me") }))
+	}))
+
+	nomoyutest.AssertStatus(t, h.GET("/api/ping"), http.StatusUnauthorized)
+	nomoyutest.AssertStatus(t, h.GET("/sec/me"), http.StatusUnauthorized)
+	token := nomoyutest.WithBearer(h.MintJWT("1", "bob"))
+	nomoyutest.AssertSuccess(t, h.GET("/api/ping", token), nil)
+	nomoyutest.AssertSuccess(t, h.GET("/sec/me", token), nil)
+	nomoyutest.AssertStatus(t, h.GET("/healthz"), http.StatusOK)
+
+	for _, r := range h.Routes() {
+		if r.Path == "/api/ping" && !r.RequireAuth {
+			t.Errorf("route table: %s not marked as authenticated", r.Path)
+		}
+	}
+}
+
+func TestConfigAuthOnlyRequireAuthGroups(t *testing.T) {
+	h := nomoyutest.New(t,
+		nomoyutest.WithConfig("auth:\n  enabled: true\n  mode: jwt\n  jwt:\n    secret: abc\n"),
+		nomoyutest.WithApp(func(a *nomoyu.App) {
+			a.WithRoute(nomoyu.NewGroup("/api").GET("/ping", func(c *gin.Context) { response.Success(c, "pong") }))
+			a.WithRoute(nomoyu.NewGroup("/sec").RequireAuth().
+				GET("/me", func(c *gin.Context) { response.Success(c, "me") }).
+				GET("/open", func(c *gin.Context) { response.Success(c, "open") }, nomoyu.Public()))
+		}))
+
+	nomoyutest.AssertSuccess(t, h.GET("/api/ping"), nil)
+	nomoyutest.AssertSuccess(t, h.GET("/sec/open"), nil)
+	nomoyutest.AssertStatus(t, h.GET("/sec/me"), http.StatusUnauthorized)
+	nomoyutest.AssertSuccess(t, h.GET("/sec/me", nomoyutest.WithBearer(h.MintJWT("1", "bob"))), nil)
+}
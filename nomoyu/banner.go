@@ -2,6 +2,7 @@ package nomoyu
 
 import (
 	"fmt"
+	"github.com/gin-gonic/gin"
 	"github.com/nomoyu/go-gin-framework/pkg/config"
 	"time"
 )
@@ -30,8 +31,8 @@ const nomoyuLogo = `
 
 // 生成 Banner 字符串
 func bannerString() string {
-	app := config.Conf.App
-	srv := config.Conf.Server
+	app := config.Current().App
+	srv := config.Current().Server
 
 	name := app.Name
 	if name == "" {
@@ -59,5 +60,8 @@ func bannerString() string {
 
 // 打印 Banner（在 init 或启动时调用）
 func printBanner() {
+	if gin.Mode() == gin.TestMode {
+		return
+	}
 	fmt.Print(bannerString())
 }
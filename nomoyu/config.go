@@ -1,20 +1,249 @@
 package nomoyu
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"time"
+
+	"github.com/nomoyu/go-gin-framework/internal/middleware"
 	"github.com/nomoyu/go-gin-framework/internal/router"
 	"github.com/nomoyu/go-gin-framework/pkg/config"
+	"github.com/nomoyu/go-gin-framework/pkg/configcenter"
+	"github.com/nomoyu/go-gin-framework/pkg/logger"
 )
 
-// InitRemoteConfigIfPresent 如果配置文件定义远程配置中心，则初始化远程配置中心
-// 需要在初始化gin框架之后再初始化，因为需要内置路由实现
-func initRemoteConfigIfPresent(a *App) {
-	if config.Conf.Config.Remote.Addr == "" {
+type ConfigCenterOption struct {
+	Dir      string // 默认取配置 config.center.dir
+	ReadOnly bool   // 与配置 config.center.read_only 任一为 true 即只读
+	FromUser bool
+}
+
+// WithConfigCenter 开启内置配置中心（等价于配置 config.center.enabled: true）
+func (a *App) WithConfigCenter(opts ...func(o *ConfigCenterOption)) *App {
+	opt := &ConfigCenterOption{FromUser: true}
+	for _, fn := range opts {
+		fn(opt)
+	}
+	a.configCenterOption = opt
+	return a
+}
+
+// ConfigCenter 返回配置中心（未开启时为 nil），需在 Build 之后调用
+func (a *App) ConfigCenter() *configcenter.Center {
+	return a.configCenter
+}
+
+// initConfigCenterIfPresent 打开存储并把本应用（app.name/app.env）的最新版本叠加到配置上，
+// 需在其他子系统读取配置之前执行
+func initConfigCenterIfPresent(a *App) error {
+	conf := config.Current().Config.Center
+	if a.configCenterOption == nil || !a.configCenterOption.FromUser {
+		if !conf.Enabled {
+			return nil
+		}
+		a.configCenterOption = &ConfigCenterOption{FromUser: false}
+	}
+	dir := firstNonZero(a.configCenterOption.Dir, conf.Dir)
+
+	store, err := configcenter.Open(dir)
+	if err != nil {
+		return err
+	}
+	app := config.Current().App
+	center := configcenter.New(store, firstNonZero(app.Name, "nomoyu-go"), firstNonZero(app.Env, config.Env(), "dev"))
+	if err := center.Apply(); err != nil {
+		return err
+	}
+	a.configCenter = center
+	return nil
+}
+
+// registerConfigCenterRoutes 在运维端口注册配置中心页面与接口：配置了 config.center.admin 专用账号时使用
+// HTTP Basic 认证，否则使用应用的认证策略并要求 admin 角色，两者都没有时拒绝启动
+func registerConfigCenterRoutes(a *App) error {
+	if a.configCenter == nil {
+		return nil
+	}
+	conf := config.Current().Config.Center
+	allow, err := middleware.ParseAllowIPs(conf.AllowIPs)
+	if err != nil {
+		return fmt.Errorf("config.center.allow_ips: %w", err)
+	}
+	access := router.ConfigAccess{
+		Username: conf.Admin.Username,
+		Password: conf.Admin.Password,
+		Role:     conf.Admin.Role,
+		AllowIPs: allow,
+		ReadOnly: a.configCenterOption.ReadOnly || conf.ReadOnly,
+	}
+	mode := "basic auth"
+	if access.Username == "" {
+		if a.authOption == nil || a.authOption.Strategy == nil {
+			return errors.New("config center needs config.center.admin.username/password or an auth strategy (WithAuth / auth.enabled)")
+		}
+		access.Strategy = a.authOption.Strategy
+		mode = "auth strategy, role " + access.Role
+	}
+	if access.ReadOnly {
+		mode += ", read-only"
+	}
+
+	router.RegisterConfigRoutes(a.opsEngine(), a.configCenter, access)
+	app, env := a.configCenter.Target()
+	logger.Infof("config center enabled: /config (%s/%s, %s)", app, env, mode)
+	return nil
+}
+
+// RemoteConfigOption 远程配置客户端参数，其余参数见配置 config.remote
+type RemoteConfigOption struct {
+	Addr     string // 配置中心地址，默认取配置 config.remote.addr
+	Token    string
+	FromUser bool
+}
+
+// WithRemoteConfig 从远程配置中心拉取本应用的文档（等价于配置 config.remote.addr/token）
+func (a *App) WithRemoteConfig(opts ...func(o *RemoteConfigOption)) *App {
+	opt := &RemoteConfigOption{FromUser: true}
+	for _, fn := range opts {
+		fn(opt)
+	}
+	a.remoteConfigOption = opt
+	return a
+}
+
+// initRemoteConfigIfPresent 启动时拉取远程文档叠加到配置上（不可用时使用磁盘缓存），之后在后台长轮询，
+// 有新版本时热更新；需在其他子系统读取配置之前执行
+func initRemoteConfigIfPresent(ctx context.Context, a *App) {
+	conf := config.Current().Config.Remote
+	opt := RemoteConfigOption{}
+	if a.remoteConfigOption != nil {
+		opt = *a.remoteConfigOption
+	}
+	opt.Addr = firstNonZero(opt.Addr, conf.Addr)
+	opt.Token = firstNonZero(opt.Token, conf.Token)
+	if opt.Addr == "" {
+		return
+	}
+
+	app := config.Current().App
+	client := configcenter.NewClient(configcenter.ClientOptions{
+		Addr:     opt.Addr,
+		App:      firstNonZero(app.Name, "nomoyu-go"),
+		Env:      firstNonZero(app.Env, config.Env(), "dev"),
+		Token:    opt.Token,
+		Interval: conf.Interval,
+		LongPoll: conf.LongPoll,
+		CacheDir: conf.CacheDir,
+		OnError: func(err error) {
+			logger.Errorf("remote config: %v", err)
+		},
+	})
+	loadCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	err := client.Load(loadCtx)
+	cancel()
+	// 日志尚未按配置初始化，先输出到标准日志
+	switch {
+	case errors.Is(err, configcenter.ErrOffline):
+		log.Printf("[remote config] %v", err)
+	case err != nil:
+		log.Printf("[remote config] %v, starting with local config only", err)
+	default:
+		log.Printf("[remote config] loaded v%d from %s", client.Version(), opt.Addr)
+	}
+	a.remoteConfig = client
+}
+
+// startRemoteConfig 后台拉取新版本，停机或 Build 失败（ctx 取消）时停止并等待轮询退出
+func startRemoteConfig(ctx context.Context, a *App) {
+	if a.remoteConfig == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.remoteConfig.Run(ctx)
+	}()
+	a.OnShutdown(func(stopCtx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-stopCtx.Done():
+			return fmt.Errorf("remote config poller: %w", stopCtx.Err())
+		}
+	})
+}
+
+// WithConfigWatch 监听配置文件变化并热更新（等价于配置 config.watch: true）
+func (a *App) WithConfigWatch() *App {
+	a.configWatch = true
+	return a
+}
+
+// initConfigWatchIfPresent 开启后配置文件变化时原子替换 config.Current()，并把日志级别等变化应用到运行中的组件；
+// 新文件解析失败时保留当前配置并记录错误
+func initConfigWatchIfPresent(a *App) {
+	a.configWatch = a.configWatch || config.Current().Config.Watch
+	if a.configWatch {
+		config.OnReloadError(func(err error) {
+			logger.Errorf("%v", err)
+		})
+		stop, err := config.Watch()
+		if err != nil {
+			logger.Warnf("config watch disabled: %v", err)
+			a.configWatch = false
+		} else {
+			a.OnShutdown(func(context.Context) error {
+				stop()
+				return nil
+			})
+		}
+	}
+	if !a.configLive() {
 		return
 	}
-	fmt.Println("[remote config]start init remote config center...")
-	// 注册路由
-	router.RegisterConfigRoutes(a.engine)
 
-	fmt.Println("[remote config]success init remote config center!")
+	a.subscribeConfig(func() func() {
+		return config.OnChange("", func(_, _ *config.AppConfig) {
+			logger.Info("config reloaded")
+		})
+	})
+	// WithLog 指定的级别不随配置变化
+	if a.logOption == nil || !a.logOption.FromUser {
+		a.subscribeConfig(func() func() {
+			return config.OnChange("log", func(old, new config.Log) {
+				if old.Level == new.Level {
+					return
+				}
+				if err := logger.SetLevel(new.Level); err != nil {
+					logger.Errorf("config reload: invalid log.level %q: %v", new.Level, err)
+					return
+				}
+				logger.Infof("config reload: log level %s -> %s", old.Level, new.Level)
+			})
+		})
+	}
+	if a.configWatch {
+		logger.Infof("config watch enabled")
+	}
+}
+
+// configLive 配置是否会在运行中变化（文件热更新、配置中心保存或远程配置更新）
+func (a *App) configLive() bool {
+	return a.configWatch || a.configCenter != nil || a.remoteConfig != nil
+}
+
+// subscribeConfig 配置会在运行中变化时订阅，停机时取消订阅
+func (a *App) subscribeConfig(subscribe func() (cancel func())) {
+	if !a.configLive() {
+		return
+	}
+	cancel := subscribe()
+	a.OnShutdown(func(ctx context.Context) error {
+		cancel()
+		return nil
+	})
 }
@@ -0,0 +1,100 @@
+package nomoyu_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/nomoyu/go-gin-framework/nomoyu"
+	"github.com/nomoyu/go-gin-framework/nomoyu/nomoyutest"
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+)
+
+func TestConfigCenterRequiresCredentials(t *testing.T) {
+	loadTestConfig(t)
+	if err := config.LoadBytes([]byte("log: {level: error, path: " + t.TempDir() + "}\nconfig: {center: {enabled: true, dir: " + t.TempDir() + "}}\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := nomoyu.New().Build(); err == nil {
+		t.Fatal("config center without admin account or auth: expected Build error")
+	}
+
+	err := config.LoadBytes([]byte("config: {center: {allow_ips: [nope, 10.0.0.0/8]}}\n"))
+	if err == nil || !strings.Contains(err.Error(), "config.center.allow_ips[0]") {
+		t.Fatalf("allow_ips: %v", err)
+	}
+}
+
+func TestConfigCenterBasicAuth(t *testing.T) {
+	h := nomoyutest.New(t, nomoyutest.WithConfig("config: {center: {enabled: true, dir: "+t.TempDir()+", admin: {username: root, password: pw}}}\n"))
+	get := func(path, user, pass string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.SetBasicAuth(user, pass)
+		return h.Do(req)
+	}
+	if rec := h.GET("/config"); rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+		t.Fatalf("no credentials: %d %v", rec.Code, rec.Header())
+	}
+	nomoyutest.AssertStatus(t, get("/config", "root", "bad"), http.StatusUnauthorized)
+	page := get("/config", "root", "pw")
+	nomoyutest.AssertStatus(t, page, http.StatusOK)
+	m := regexp.MustCompile(`name="csrf_token" value="([^"]+)"`).FindStringSubmatch(page.Body.String())
+	if m == nil {
+		t.Fatalf("page has no csrf token:\n%s", page.Body.String())
+	}
+
+	post := func(token string) *httptest.ResponseRecorder {
+		form := url.Values{"config": {"log:\n  level: warn\n"}, "csrf_token": {token}}
+		req := httptest.NewRequest(http.MethodPost, "/config/save", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth("root", "pw")
+		return h.Do(req)
+	}
+	_, mac, _ := strings.Cut(m[1], ".")
+	for _, token := range []string{"", "abc.def", "1." + mac} {
+		nomoyutest.AssertStatus(t, post(token), http.StatusForbidden)
+	}
+	if rec := post(m[1]); rec.Code != http.StatusSeeOther || config.Current().Log.Level != "warn" {
+		t.Fatalf("save: %d, level %s", rec.Code, config.Current().Log.Level)
+	}
+	if body := get("/config/audit", "root", "pw").Body.String(); !strings.Contains(body, `"actor":"root"`) {
+		t.Fatalf("audit actor: %s", body)
+	}
+
+	// 远程配置接口使用 token 认证，不接受管理员账号
+	if rec := h.GET("/config/remote/nomoyutest/test"); rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "token") {
+		t.Fatalf("remote: %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestConfigCenterRoleAndAllowIPs(t *testing.T) {
+	h := nomoyutest.New(t, nomoyutest.WithConfig("auth: {enabled: true, jwt: {secret: s}}\n"+
+		"config: {center: {enabled: true, dir: "+t.TempDir()+", read_only: true, token: tk, allow_ips: [192.0.2.0/24, '::1']}}\n"))
+	nomoyutest.AssertStatus(t, h.GET("/config"), http.StatusUnauthorized)
+	nomoyutest.AssertStatus(t, h.GET("/config", nomoyutest.WithBearer(h.MintJWT("1", "bob", "user"))), http.StatusForbidden)
+
+	admin := nomoyutest.WithBearer(h.MintJWT("1", "bob", "admin"))
+	rec := h.GET("/config", admin)
+	nomoyutest.AssertStatus(t, rec, http.StatusOK)
+	if strings.Contains(rec.Body.String(), "/config/save") || !strings.Contains(rec.Body.String(), "只读") {
+		t.Fatal("read-only page still offers saving")
+	}
+	nomoyutest.AssertStatus(t, h.POST("/config/save", map[string]any{"config": "log: {level: warn}"}, admin), http.StatusForbidden)
+
+	// 按直连地址判断，忽略 X-Forwarded-For
+	req := nomoyutest.NewRequest(t, http.MethodGet, "/config/json", nil, admin, nomoyutest.WithHeader("X-Forwarded-For", "192.0.2.7"))
+	req.RemoteAddr = "10.1.1.1:1234"
+	nomoyutest.AssertStatus(t, h.Do(req), http.StatusForbidden)
+	req.RemoteAddr = "[::1]:1234"
+	nomoyutest.AssertStatus(t, h.Do(req), http.StatusOK)
+
+	// 远程拉取接口使用独立的令牌，但同样受来源名单限制
+	req = nomoyutest.NewRequest(t, http.MethodGet, "/config/remote/nomoyutest/test", nil, nomoyutest.WithBearer("tk"))
+	req.RemoteAddr = "10.1.1.1:1234"
+	nomoyutest.AssertStatus(t, h.Do(req), http.StatusForbidden)
+	req.RemoteAddr = "192.0.2.7:1234"
+	nomoyutest.AssertStatus(t, h.Do(req), http.StatusNotFound) // 通过检查，该应用还没有文档
+}
@@ -0,0 +1,120 @@
+package nomoyu_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/nomoyu/go-gin-framework/nomoyu/nomoyutest"
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+	"github.com/nomoyu/go-gin-framework/pkg/errorcode"
+	"github.com/nomoyu/go-gin-framework/pkg/logger"
+)
+
+func newCenterApp(t *testing.T) (*nomoyutest.App, nomoyutest.RequestOption) {
+	t.Helper()
+	h := nomoyutest.New(t, nomoyutest.WithConfig(
+		"app: {name: capp, env: test}\nconfig: {center: {enabled: true, dir: "+t.TempDir()+"}}\nauth: {enabled: true, jwt: {secret: base}}\n"))
+	return h, nomoyutest.WithBearer(h.MintJWT("1", "alice", "admin"))
+}
+
+func TestConfigCenterSaveAndRollback(t *testing.T) {
+	h, bearer := newCenterApp(t)
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/config/save", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		bearer(req)
+		return h.Do(req)
+	}
+
+	rec := post(url.Values{"config": {"# comment\nlog:\n  level: warn\nauth:\n  jwt:\n    secret: topsecret\n"}, "comment": {"first"}})
+	if rec.Code != http.StatusSeeOther || !strings.Contains(rec.Header().Get("Location"), "v1") {
+		t.Fatalf("save: %d %s", rec.Code, rec.Header().Get("Location"))
+	}
+	if config.Current().Log.Level != "warn" || logger.GetLevel() != "warn" || config.Current().Auth.JWT.Secret != "topsecret" {
+		t.Fatalf("not applied: %+v", config.Current().Log)
+	}
+
+	// 页面中密钥脱敏，原样提交 ****** 保留原值
+	page := h.GET("/config", bearer).Body.String()
+	if strings.Contains(page, "topsecret") || !strings.Contains(page, "******") || !strings.Contains(page, "# comment") {
+		t.Fatalf("page not masked:\n%s", page)
+	}
+	post(url.Values{"config": {"# comment\nlog:\n  level: error\nauth:\n  jwt:\n    secret: '******'\n"}})
+	if config.Current().Auth.JWT.Secret != "topsecret" || config.Current().Log.Level != "error" {
+		t.Fatalf("masked secret not restored: %+v", config.Current().Log)
+	}
+
+	res := nomoyutest.AssertCode(t, h.POST("/config/save", map[string]any{"config": "log:\n  level: nope\nserver:\n  port: 70000\n"}, bearer),
+		errorcode.InvalidParams.Code, nil)
+	if !strings.Contains(res.Msg, "log.level") || !strings.Contains(res.Msg, "server.port") || config.Current().Log.Level != "error" {
+		t.Fatalf("invalid document: %s", res.Msg)
+	}
+
+	nomoyutest.AssertSuccess(t, h.POST("/config/rollback/1", map[string]any{}, bearer), nil)
+	if config.Current().Log.Level != "warn" {
+		t.Fatalf("rollback: %+v", config.Current().Log)
+	}
+	for _, path := range []string{"/config/versions", "/config/audit", "/config/diff?from=1&to=2", "/config/json", "/config/document?version=1"} {
+		if body := h.GET(path, bearer).Body.String(); strings.Contains(body, "topsecret") {
+			t.Fatalf("%s leaks secret: %s", path, body)
+		}
+	}
+	if body := h.GET("/config?from=1&to=2", bearer).Body.String(); !strings.Contains(body, `<span class="add">+   level: error</span>`) {
+		t.Fatalf("diff not rendered:\n%s", body)
+	}
+}
+
+func TestConfigCenterForeignDocument(t *testing.T) {
+	h, bearer := newCenterApp(t)
+	save := func(doc string) *httptest.ResponseRecorder {
+		return h.POST("/config/save", map[string]any{"app": "other", "env": "prod", "config": doc}, bearer)
+	}
+
+	// 文档中出现的键按相同规则校验
+	res := nomoyutest.AssertCode(t, save("log:\n  level: nope\nserver:\n  port: 70000\n"), errorcode.InvalidParams.Code, nil)
+	if !strings.Contains(res.Msg, "log.level") || !strings.Contains(res.Msg, "server.port") {
+		t.Fatalf("foreign document not validated: %s", res.Msg)
+	}
+	nomoyutest.AssertCode(t, save("server:\n  port: abc\n"), errorcode.InvalidParams.Code, nil)
+	nomoyutest.AssertCode(t, save("log:\n  path: ${env:HOME}\n"), errorcode.InvalidParams.Code, nil)
+
+	// 文档以外的必填项（jwt.secret 在该应用的本地配置中）不报告，本应用配置不受影响
+	nomoyutest.AssertSuccess(t, save("auth:\n  enabled: true\nlog:\n  level: debug\n"), nil)
+	if config.Current().Log.Level == "debug" {
+		t.Fatal("foreign document applied to this app")
+	}
+}
+
+func TestConfigCenterInvalidQuery(t *testing.T) {
+	h, bearer := newCenterApp(t)
+	nomoyutest.AssertSuccess(t, h.POST("/config/save", map[string]any{"config": "log:\n  level: warn\n"}, bearer), nil)
+	for _, path := range []string{"/config/document?version=x", "/config/diff?from=1&to=x", "/config/audit?limit=x", "/config/audit?limit=-1"} {
+		nomoyutest.AssertCode(t, h.GET(path, bearer), errorcode.InvalidParams.Code, nil)
+	}
+	nomoyutest.AssertSuccess(t, h.GET("/config/audit?limit=1", bearer), nil)
+}
+
+type centerPayment struct {
+	MerchantID string `mapstructure:"merchant_id"`
+	Secret     string `mapstructure:"secret" secret:"true"`
+}
+
+var centerPaymentSection = config.Register("center_payment", centerPayment{})
+
+func TestConfigCenterMasksRegisteredSection(t *testing.T) {
+	h, bearer := newCenterApp(t)
+	nomoyutest.AssertSuccess(t, h.POST("/config/save",
+		map[string]any{"config": "center_payment:\n  merchant_id: m1\n  secret: plain\n"}, bearer), nil)
+	if centerPaymentSection.Get().Secret != "plain" {
+		t.Fatalf("not applied: %+v", centerPaymentSection.Get())
+	}
+	for _, path := range []string{"/config/json", "/config/document"} {
+		body := h.GET(path, bearer).Body.String()
+		if strings.Contains(body, "plain") || !strings.Contains(body, "m1") {
+			t.Fatalf("%s: registered secret not masked:\n%s", path, body)
+		}
+	}
+}
@@ -2,9 +2,12 @@
 package nomoyu
 
 import (
+	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
 	"github.com/nomoyu/go-gin-framework/internal/middleware"
 	"github.com/nomoyu/go-gin-framework/pkg/config"
 	"github.com/nomoyu/go-gin-framework/pkg/logger"
@@ -27,41 +30,79 @@ func (a *App) WithCORS(opt CORSOption) *App {
 }
 
 func initCORS(app *App) {
-	// 用户显式 WithCORS 优先
-	if app.corsOption == nil || !app.corsOption.FromUser {
-		conf := config.Conf.CORS
-		if conf.Enabled {
-			app.corsOption = &CORSOption{
-				AllowOrigins:     conf.AllowOrigins,
-				AllowMethods:     firstOr(conf.AllowMethods, []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-				AllowHeaders:     firstOr(conf.AllowHeaders, []string{"Origin", "Content-Type", "Accept", "Authorization"}),
-				ExposeHeaders:    conf.ExposeHeaders,
-				AllowCredentials: conf.AllowCredentials,
-				MaxAge:           time.Duration(conf.MaxAge) * time.Second,
-				FromUser:         false,
-			}
-		}
+	// 用户显式 WithCORS 优先，不随配置热更新
+	if app.corsOption != nil && app.corsOption.FromUser {
+		app.engine.Use(corsHandler(app.corsOption))
+		logger.Infof("CORS enabled: origins=%v, credentials=%v", app.corsOption.AllowOrigins, app.corsOption.AllowCredentials)
+		return
 	}
 
-	if app.corsOption == nil {
-		// 未启用
-		app.engine.Use(middleware.DefaultCORS())
-		return
+	app.corsOption = corsOptionFromConfig(config.Current().CORS)
+	if app.corsOption != nil {
+		logger.Infof("CORS enabled: origins=%v, credentials=%v", app.corsOption.AllowOrigins, app.corsOption.AllowCredentials)
 	}
 
-	// 构建 gin-contrib/cors.Config
-	cfg := cors.Config{
-		AllowOrigins:     app.corsOption.AllowOrigins,
-		AllowMethods:     app.corsOption.AllowMethods,
-		AllowHeaders:     app.corsOption.AllowHeaders,
-		ExposeHeaders:    app.corsOption.ExposeHeaders,
-		AllowCredentials: app.corsOption.AllowCredentials,
-		MaxAge:           app.corsOption.MaxAge,
+	// 配置 cors.* 变化时替换处理函数
+	var current atomic.Pointer[gin.HandlerFunc]
+	h := corsHandler(app.corsOption)
+	current.Store(&h)
+	app.subscribeConfig(func() func() {
+		return config.OnChange("cors", func(_, conf config.CORS) {
+			opt := corsOptionFromConfig(conf)
+			h, err := safeCORSHandler(opt)
+			if err != nil {
+				logger.Errorf("config reload: cors rejected: %v", err)
+				return
+			}
+			current.Store(&h)
+			logger.Infof("config reload: cors updated (enabled=%v, origins=%v)", conf.Enabled, conf.AllowOrigins)
+		})
+	})
+	app.engine.Use(func(c *gin.Context) {
+		(*current.Load())(c)
+	})
+}
+
+// corsOptionFromConfig 配置未启用时返回 nil
+func corsOptionFromConfig(conf config.CORS) *CORSOption {
+	if !conf.Enabled {
+		return nil
 	}
+	return &CORSOption{
+		AllowOrigins:     conf.AllowOrigins,
+		AllowMethods:     conf.AllowMethods,
+		AllowHeaders:     conf.AllowHeaders,
+		ExposeHeaders:    conf.ExposeHeaders,
+		AllowCredentials: conf.AllowCredentials,
+		MaxAge:           time.Duration(conf.MaxAge) * time.Second,
+		FromUser:         false,
+	}
+}
 
-	// 注册到 engine（建议尽早挂载）
-	app.engine.Use(middleware.CORSMiddleware(cfg))
-	logger.Infof("CORS enabled: origins=%v, credentials=%v", cfg.AllowOrigins, cfg.AllowCredentials)
+// corsHandler opt 为 nil（未启用）时使用兜底的 DefaultCORS
+func corsHandler(opt *CORSOption) gin.HandlerFunc {
+	if opt == nil {
+		return middleware.DefaultCORS()
+	}
+	// 构建 gin-contrib/cors.Config
+	return middleware.CORSMiddleware(cors.Config{
+		AllowOrigins:     opt.AllowOrigins,
+		AllowMethods:     opt.AllowMethods,
+		AllowHeaders:     opt.AllowHeaders,
+		ExposeHeaders:    opt.ExposeHeaders,
+		AllowCredentials: opt.AllowCredentials,
+		MaxAge:           opt.MaxAge,
+	})
+}
+
+// safeCORSHandler cors.New 对非法配置直接 panic，热更新时转换为错误并保留旧配置
+func safeCORSHandler(opt *CORSOption) (h gin.HandlerFunc, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return corsHandler(opt), nil
 }
 
 func firstOr[T any](v []T, def []T) []T {
@@ -1,7 +1,9 @@
 package nomoyu
 
 import (
+	"context"
 	"fmt"
+	"github.com/gin-gonic/gin"
 	"github.com/nomoyu/go-gin-framework/pkg/config"
 	"github.com/nomoyu/go-gin-framework/pkg/db"
 	"github.com/nomoyu/go-gin-framework/pkg/logger"
@@ -29,40 +31,57 @@ func (a *App) WithDB(dialect, dsn string, opts ...func(*db.Option)) *App {
 	return a
 }
 
-// 初始化数据库
-func initDBIfPresent(a *App) {
+// dbModule 内置数据库模块（模块名 "db"），其他模块可通过 DependsOn 声明依赖
+type dbModule struct {
+	opt         db.Option
+	autoMigrate bool
+	fromUser    bool
+}
+
+func (m *dbModule) Name() string           { return "db" }
+func (m *dbModule) Register(r *gin.Engine) {}
+
+func (m *dbModule) Init(ctx context.Context, app *App) error {
+	if err := db.Init(m.opt); err != nil {
+		return err
+	}
+	if m.fromUser {
+		logger.Info("init nomoyu database success (WithDB)...")
+	} else {
+		logger.Info("init nomoyu database success...")
+	}
+	if !m.autoMigrate {
+		return nil
+	}
+	if err := db.AutoMigrate(); err != nil {
+		return fmt.Errorf("autoMigrate fail: %w", err)
+	}
+	return nil
+}
+
+func (m *dbModule) Stop(ctx context.Context) error {
+	logger.Info("close database connect...")
+	return db.Close()
+}
+
+// 根据 WithDB 或配置文件生成数据库模块，未配置时返回 nil
+func newDBModuleIfPresent(a *App) (Module, error) {
+	conf := config.Current().Database
+
 	// 1) 用户手动指定
 	if a.dbOption != nil && a.dbOption.FromUser {
-		if err := db.Init(a.dbOption.Opt); err != nil {
-			fmt.Println("database initialization failed (WithDB):", err)
-		} else {
-			fmt.Println("database connection succeeded (WithDB)")
-		}
-		return
+		return &dbModule{opt: a.dbOption.Opt, autoMigrate: conf.AutoMigrate, fromUser: true}, nil
 	}
 
 	// 2) 配置文件自动
-	conf := config.Conf.Database
-	if conf.Dialect != "" && (conf.Host != "" || conf.Dialect == "sqlite") {
-		dsn, err := db.BuildDSN(conf.Dialect, conf.Host, conf.Port, conf.User, conf.Password, conf.DBName)
-		if err != nil {
-			fmt.Println("failed to generate DSN:", err)
-			return
-		}
-		opt := db.Option{Dialect: conf.Dialect, DSN: dsn}
-		if err := db.Init(opt); err != nil {
-			fmt.Println("init nomoyu database fail...", err)
-		} else {
-			logger.Info("init nomoyu database success...")
-			if !conf.AutoMigrate {
-				return
-			}
-			err := db.AutoMigrate()
-			if err != nil {
-				logger.Infof("autoMigrate fail: %v", err)
-				return
-			}
-			logger.Info("autoMigrate success")
-		}
+	if conf.Dialect == "" || (conf.Host == "" && conf.Dialect != "sqlite") {
+		return nil, nil
+	}
+	dsn, err := db.BuildDSN(conf.Dialect, conf.Host, conf.Port, conf.User, conf.Password, conf.DBName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate DSN: %w", err)
 	}
+	opt := db.Option{Dialect: conf.Dialect, DSN: dsn}
+	a.dbOption = &DBOption{Opt: opt, FromUser: false}
+	return &dbModule{opt: opt, autoMigrate: conf.AutoMigrate}, nil
 }
@@ -3,14 +3,16 @@ package nomoyu
 import (
 	"context"
 	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/pkg/logger"
 	"net/http"
 	"sync/atomic"
+	"time"
 )
 
-// 关机防护中间件：关机中直接 503，避免新请求排队
+// 关机防护中间件：关机中直接 503，避免新请求排队（摘流量等待期间与探针除外）
 func (a *App) shutdownGuard() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if atomic.LoadInt32(&a.shutting) == 1 {
+		if atomic.LoadInt32(&a.shutting) == 1 && atomic.LoadInt32(&a.draining) == 0 && !isProbePath(c.Request.URL.Path) {
 			c.Header("Connection", "close")
 			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
 				"code":    http.StatusServiceUnavailable,
@@ -27,3 +29,82 @@ func (a *App) OnShutdown(fn func(ctx context.Context) error) *App {
 	a.shutdownHooks = append(a.shutdownHooks, fn)
 	return a
 }
+
+// Shutdown 优雅停机并等待完成：Run 中的应用停止监听并返回；只 Build 未 Run 的应用（如测试）直接执行停机钩子
+// （模块 Stop、取消配置订阅等）并关闭 SSE/WebSocket 连接。可重复调用，只执行一次
+func (a *App) Shutdown() {
+	a.stopOnce.Do(func() { close(a.stop) })
+	if atomic.LoadInt32(&a.serving) == 1 {
+		<-a.served
+		return
+	}
+	a.shutdown()
+}
+
+// runShutdownHooks 倒序执行 from 之后登记的停机钩子并移除（停机、Build 失败时调用）
+func (a *App) runShutdownHooks(ctx context.Context, from int) {
+	hooks := a.shutdownHooks[from:]
+	a.shutdownHooks = a.shutdownHooks[:from]
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](ctx); err != nil {
+			logger.Warnf("shutdown hook error: %v", err)
+		}
+	}
+}
+
+// shutdown 优雅停机（只执行一次）：标记关机 -> 关闭 HTTP -> 倒序执行清理钩子（含模块 Stop）
+func (a *App) shutdown() {
+	a.shutdownOnce.Do(a.doShutdown)
+}
+
+func (a *App) doShutdown() {
+	// 标记进入关机：/readyz 立即失败；配置了摘流量等待时先继续处理请求
+	if a.drainDelay > 0 && a.httpServer != nil {
+		atomic.StoreInt32(&a.draining, 1)
+		atomic.StoreInt32(&a.shutting, 1)
+		logger.Infof("readiness failing, waiting %s for load balancers to drain...", a.drainDelay)
+		time.Sleep(a.drainDelay)
+		atomic.StoreInt32(&a.draining, 0)
+	}
+	// 拒绝新请求
+	atomic.StoreInt32(&a.shutting, 1)
+
+	// 关机超时上下文
+	timeout := a.shutdownTimeout
+	if timeout <= 0 {
+		timeout = *resolveServerOption(a).ShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// SSE 长连接不会变为空闲，先结束事件流，否则 Shutdown 会一直等到超时
+	if a.sseBroker != nil {
+		a.sseBroker.Close()
+	}
+
+	// 优雅关闭 HTTP
+	if a.httpServer != nil {
+		if err := a.httpServer.Shutdown(ctx); err != nil {
+			logger.Errorf("http server shutdown error: %v", err)
+		} else {
+			logger.Infof("http server shutdown gracefully")
+		}
+	}
+	// http.Server 不跟踪已升级的 WebSocket 连接，需要单独发送关闭帧并等待 handler 退出
+	if a.wsHub != nil {
+		if err := a.wsHub.Close(ctx); err != nil {
+			logger.Warnf("websocket connections not closed in time: %v", err)
+		}
+	}
+	// 管理端最后关闭，停机过程中仍可排查
+	if a.adminServer != nil {
+		if err := a.adminServer.Shutdown(ctx); err != nil {
+			logger.Errorf("admin server shutdown error: %v", err)
+		}
+	}
+
+	// 倒序执行清理钩子（含模块 Stop：模块在依赖之后 Init，先于依赖停止）
+	a.runShutdownHooks(ctx, 0)
+
+	logger.Infof("graceful shutdown done, bye 👋")
+}
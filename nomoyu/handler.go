@@ -0,0 +1,203 @@
+package nomoyu
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/nomoyu/go-gin-framework/pkg/errorcode"
+	"github.com/nomoyu/go-gin-framework/pkg/logger"
+	"github.com/nomoyu/go-gin-framework/pkg/response"
+)
+
+// HandlerFunc 类型化的业务处理函数
+type HandlerFunc[Req, Resp any] func(ctx context.Context, req *Req) (*Resp, error)
+
+// HandlerTypes 类型化 handler 的请求/响应类型，供文档等工具使用
+type HandlerTypes struct {
+	Request  reflect.Type
+	Response reflect.Type
+	Name     string // 业务函数名（Handle 返回的闭包本身没有可读的名字）
+}
+
+// TypedHandler Handle 创建的类型化 handler：通过 RouteGroup.Typed 注册时记录请求/响应类型（OpenAPI、路由表使用），
+// 直接挂到 gin 或 RouteGroup.GET 等方法上时使用 Gin()
+type TypedHandler struct {
+	handler gin.HandlerFunc
+	types   HandlerTypes
+}
+
+// Gin 返回适配后的 gin.HandlerFunc
+func (h TypedHandler) Gin() gin.HandlerFunc {
+	return h.handler
+}
+
+// Types 返回请求/响应类型
+func (h TypedHandler) Types() HandlerTypes {
+	return h.types
+}
+
+// Handle 把类型化函数适配为 gin.HandlerFunc：
+//
+//   - 有请求体时先按 Content-Type 绑定 JSON/表单，再依次绑定 form（query）、header、uri（路径参数）标签，
+//     后绑定的来源优先，请求体无法覆盖路径参数；query 与请求头只绑定到显式声明了 form/header 标签的字段
+//   - 全部来源绑定完成后统一执行 binding 校验规则，失败返回 errorcode.InvalidParams
+//   - 返回 errorcode.ErrorCode（可被 %w 包装）时按错误码响应，其他错误记录日志并返回 ServerError
+//   - 成功时包装为 response.Response；handler 已自行写出响应（如文件下载）时不再包装
+func Handle[Req, Resp any](fn HandlerFunc[Req, Resp]) TypedHandler {
+	h := func(c *gin.Context) {
+		req := new(Req)
+		if err := bindRequest(c, req); err != nil {
+			response.Fail(c, errorcode.InvalidParams.Code, errorcode.InvalidParams.Msg+": "+err.Error())
+			return
+		}
+
+		resp, err := fn(c.Request.Context(), req)
+		if c.Writer.Written() {
+			return
+		}
+		if err != nil {
+			failWithError(c, err)
+			return
+		}
+		if resp == nil {
+			response.Success(c, nil)
+			return
+		}
+		response.Success(c, resp)
+	}
+
+	return TypedHandler{handler: h, types: HandlerTypes{
+		Request:  reflect.TypeOf((*Req)(nil)).Elem(),
+		Response: reflect.TypeOf((*Resp)(nil)).Elem(),
+		Name:     funcName(fn),
+	}}
+}
+
+// failWithError errorcode.ErrorCode 按错误码响应，其他错误记录日志并返回 ServerError
+func failWithError(c *gin.Context, err error) {
+	if ec, ok := errorcode.From(err); ok {
+		response.Fail(c, ec.Code, ec.Msg)
+		return
+	}
+	logger.Errorf("%s %s: %v", c.Request.Method, c.FullPath(), err)
+	response.FailWithCode(c, errorcode.ServerError)
+}
+
+// bindRequest 各来源单独绑定时忽略校验错误（必填字段可能来自其他来源），最后统一校验；
+// 请求体最先绑定、路径参数最后绑定，避免 PUT /users/1 携带 {"id": 2} 时操作的是 2 号用户
+func bindRequest(c *gin.Context, req any) error {
+	if hasBody(c.Request) {
+		b := binding.Default(c.Request.Method, c.ContentType())
+		if b == binding.Form {
+			// binding.Form 会合并 query，表单请求体只绑定 body
+			b = binding.FormPost
+		}
+		if err := ignoreValidation(c.ShouldBindWith(req, b)); err != nil {
+			return err
+		}
+	}
+	query := c.Request.URL.Query()
+	if err := bindTagged(req, "form", func(name string) []string { return query[name] }); err != nil {
+		return err
+	}
+	if err := bindTagged(req, "header", c.Request.Header.Values); err != nil {
+		return err
+	}
+	if len(c.Params) > 0 {
+		if err := ignoreValidation(c.ShouldBindUri(req)); err != nil {
+			return err
+		}
+	}
+	if binding.Validator == nil {
+		return nil
+	}
+	return binding.Validator.ValidateStruct(req)
+}
+
+// bindTagged 只把显式声明了 tag 的字段交给 gin 绑定：gin 对没有 tag 的字段按字段名取值，
+// 直接绑定 query/请求头时 ?Name=x 会覆盖请求体中的 name
+func bindTagged(req any, tag string, get func(name string) []string) error {
+	names := taggedNames(reflect.TypeOf(req), tag)
+	if len(names) == 0 {
+		return nil
+	}
+	src := make(map[string][]string, len(names))
+	for _, name := range names {
+		if values := get(name); len(values) > 0 {
+			src[name] = values
+		}
+	}
+	return binding.MapFormWithTag(req, src, tag)
+}
+
+type taggedKey struct {
+	t   reflect.Type
+	tag string
+}
+
+// taggedNamesCache taggedKey -> []string
+var taggedNamesCache sync.Map
+
+// taggedNames 返回 t（含嵌套结构体）中显式声明的 tag 名
+func taggedNames(t reflect.Type, tag string) []string {
+	key := taggedKey{t, tag}
+	if names, ok := taggedNamesCache.Load(key); ok {
+		return names.([]string)
+	}
+	var names []string
+	collectTagged(t, tag, map[reflect.Type]bool{}, &names)
+	taggedNamesCache.Store(key, names)
+	return names
+}
+
+func collectTagged(t reflect.Type, tag string, seen map[reflect.Type]bool, names *[]string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		value, ok := f.Tag.Lookup(tag)
+		if value == "-" {
+			continue
+		}
+		if ok {
+			// 与 gin 一致：只写了选项（如 form:",default=1"）时取字段名
+			name, _, _ := strings.Cut(value, ",")
+			if name == "" {
+				name = f.Name
+			}
+			*names = append(*names, name)
+		}
+		collectTagged(f.Type, tag, seen, names)
+	}
+}
+
+func ignoreValidation(err error) error {
+	var ve validator.ValidationErrors
+	if errors.As(err, &ve) {
+		return nil
+	}
+	return err
+}
+
+func hasBody(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		return false
+	}
+	return r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+}
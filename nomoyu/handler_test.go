@@ -0,0 +1,132 @@
+package nomoyu_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/nomoyu"
+	"github.com/nomoyu/go-gin-framework/nomoyu/nomoyutest"
+	"github.com/nomoyu/go-gin-framework/pkg/errorcode"
+)
+
+type updateReq struct {
+	ID    int    `uri:"id" json:"id" form:"id" binding:"required,min=1"`
+	Query string `form:"q"`
+	Token string `header:"X-Token"`
+	Name  string `json:"name" binding:"required"`
+}
+
+type updateResp struct {
+	ID    int
+	Query string
+	Token string
+	Name  string
+}
+
+func update(ctx context.Context, r *updateReq) (*updateResp, error) {
+	switch r.ID {
+	case 404:
+		return nil, fmt.Errorf("find: %w", errorcode.UserNotFound)
+	case 500:
+		return nil, errors.New("boom")
+	}
+	return &updateResp{r.ID, r.Query, r.Token, r.Name}, nil
+}
+
+type empty struct{}
+
+func list(ctx context.Context, _ *empty) (*[]int, error) {
+	return &[]int{1, 2}, nil
+}
+
+func TestHandle(t *testing.T) {
+	h := nomoyutest.New(t, nomoyutest.WithApp(func(a *nomoyu.App) {
+		a.WithRoute(nomoyu.NewGroup("/users").
+			Typed(http.MethodPut, "/:id", nomoyu.Handle(update)).
+			Typed(http.MethodGet, "", nomoyu.Handle(list)).
+			GET("/plain", func(c *gin.Context) { c.String(200, "plain") }))
+	}))
+
+	var out updateResp
+	nomoyutest.AssertSuccess(t, h.Do(nomoyutest.NewRequest(t, "PUT", "/users/7?q=hi", map[string]any{"name": "bob"},
+		nomoyutest.WithHeader("X-Token", "tk"))), &out)
+	if out != (updateResp{7, "hi", "tk", "bob"}) {
+		t.Fatalf("bound %+v", out)
+	}
+
+	// 请求体与 query 中的 id 不能覆盖路径参数
+	nomoyutest.AssertSuccess(t, h.Do(nomoyutest.NewRequest(t, "PUT", "/users/1?id=3", map[string]any{"id": 2, "name": "x"})), &out)
+	if out.ID != 1 {
+		t.Fatalf("path id overwritten: %d", out.ID)
+	}
+
+	for path, code := range map[string]int{
+		"/users/7":   errorcode.InvalidParams.Code, // name required
+		"/users/0":   errorcode.InvalidParams.Code,
+		"/users/abc": errorcode.InvalidParams.Code,
+		"/users/404": errorcode.UserNotFound.Code,
+		"/users/500": errorcode.ServerError.Code,
+	} {
+		body := map[string]any{"name": "x"}
+		if path == "/users/7" {
+			body = map[string]any{}
+		}
+		nomoyutest.AssertCode(t, h.Do(nomoyutest.NewRequest(t, "PUT", path, body)), code, nil)
+	}
+
+	var items []int
+	nomoyutest.AssertSuccess(t, h.GET("/users"), &items)
+	if len(items) != 2 {
+		t.Fatalf("list %v", items)
+	}
+
+	found := 0
+	for _, r := range h.Routes() {
+		switch r.Path {
+		case "/users/:id":
+			found++
+			if r.Request != reflect.TypeOf(updateReq{}) || r.Response != reflect.TypeOf(updateResp{}) || !strings.HasSuffix(r.Handler, "update") {
+				t.Errorf("route %s: %v %v %s", r.Path, r.Request, r.Response, r.Handler)
+			}
+		case "/users/plain":
+			found++
+			if r.Request != nil {
+				t.Errorf("plain route has request type %v", r.Request)
+			}
+		}
+	}
+	if found != 2 {
+		t.Fatalf("routes not found: %d", found)
+	}
+
+	if ty := nomoyu.Handle(update).Types(); ty.Request != reflect.TypeOf(updateReq{}) {
+		t.Fatalf("Types() = %+v", ty)
+	}
+}
+
+func TestHandleBindsTaggedQueryAndHeaderOnly(t *testing.T) {
+	h := nomoyutest.New(t, nomoyutest.WithApp(func(a *nomoyu.App) {
+		a.WithRoute(nomoyu.NewGroup("/users").Typed(http.MethodPut, "/:id", nomoyu.Handle(update)))
+	}))
+
+	// 未声明 form/header 标签的字段不接受 query 与请求头
+	var out updateResp
+	nomoyutest.AssertSuccess(t, h.Do(nomoyutest.NewRequest(t, "PUT", "/users/7?Name=evil&name=evil&Token=evil", map[string]any{"name": "bob"},
+		nomoyutest.WithHeader("Name", "evil"))), &out)
+	if out != (updateResp{7, "", "", "bob"}) {
+		t.Fatalf("bound %+v", out)
+	}
+
+	// 表单请求体只绑定 body，不合并 query
+	nomoyutest.AssertSuccess(t, h.Do(nomoyutest.NewRequest(t, "PUT", "/users/7?Token=evil", "Name=bob",
+		nomoyutest.WithHeader("Content-Type", "application/x-www-form-urlencoded"))), &out)
+	if out != (updateResp{7, "", "", "bob"}) {
+		t.Fatalf("form body bound %+v", out)
+	}
+}
@@ -0,0 +1,92 @@
+package nomoyu
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/pkg/db"
+	"github.com/nomoyu/go-gin-framework/pkg/health"
+	"github.com/nomoyu/go-gin-framework/pkg/redisx"
+)
+
+const (
+	livenessPath       = "/healthz"
+	readinessPath      = "/readyz"
+	readinessTimeout   = 3 * time.Second
+	shuttingDownReason = "server is shutting down"
+)
+
+// HealthChecker 就绪检查项；实现了该接口的模块会被自动纳入 /readyz
+type HealthChecker = health.Checker
+
+// WithHealthCheck 注册自定义就绪检查项（如下游服务、消息队列）
+func (a *App) WithHealthCheck(checkers ...HealthChecker) *App {
+	a.healthCheckers = append(a.healthCheckers, checkers...)
+	return a
+}
+
+// WithDrainDelay 停机时先让 /readyz 返回 503 并继续处理请求 d 时长，
+// 给负载均衡摘除实例的时间，之后才关闭 HTTP 服务
+func (a *App) WithDrainDelay(d time.Duration) *App {
+	a.drainDelay = d
+	return a
+}
+
+// collectHealthCheckers 把实现了 HealthChecker 的模块（内置 db/redis/scheduler）排在自定义检查项之前
+func (a *App) collectHealthCheckers() {
+	var checkers []HealthChecker
+	for _, m := range a.modules {
+		if hc, ok := m.(HealthChecker); ok {
+			checkers = append(checkers, hc)
+		}
+	}
+	a.healthCheckers = append(checkers, a.healthCheckers...)
+}
+
+// registerHealthRoutes 注册存活（/healthz）与就绪（/readyz）探针
+func (a *App) registerHealthRoutes() {
+	a.engine.GET(livenessPath, a.liveness)
+	a.engine.GET(readinessPath, a.readiness)
+}
+
+func (a *App) liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, health.Report{Status: health.StatusUp})
+}
+
+func (a *App) readiness(c *gin.Context) {
+	if atomic.LoadInt32(&a.shutting) == 1 {
+		c.JSON(http.StatusServiceUnavailable, health.Report{
+			Status: health.StatusDown,
+			Components: []health.ComponentReport{
+				{Name: "server", Status: health.StatusDown, Latency: "0s", Error: shuttingDownReason},
+			},
+		})
+		return
+	}
+
+	report := health.Run(c.Request.Context(), readinessTimeout, a.healthCheckers...)
+	status := http.StatusOK
+	if !report.Up() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+func isProbePath(path string) bool {
+	return path == livenessPath || path == readinessPath
+}
+
+func (m *dbModule) Check(ctx context.Context) error {
+	return db.Ping(ctx)
+}
+
+func (m *redisModule) Check(ctx context.Context) error {
+	return redisx.Ping(ctx)
+}
+
+func (m *schedulerModule) Check(ctx context.Context) error {
+	return health.Scheduler(m.opt.Instances...).Check(ctx)
+}
@@ -0,0 +1,174 @@
+package nomoyu_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/nomoyu"
+	"github.com/nomoyu/go-gin-framework/pkg/health"
+)
+
+// freeAddr 返回一个当前空闲的本机地址
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+// startApp 在 addr 上 Run，等到 /healthz 可访问后返回；stop 调用 Shutdown 并返回 Run 的结果
+func startApp(t *testing.T, app *nomoyu.App, addr string) (stop func() error) {
+	t.Helper()
+	return runApp(t, app, func() error { return app.Run(addr) }, func() error {
+		resp, err := http.Get("http://" + addr + "/healthz")
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	})
+}
+
+// runApp 在后台执行 run（Run / RunListener），等到 ready 返回 nil 后返回 stop
+func runApp(t *testing.T, app *nomoyu.App, run func() error, ready func() error) (stop func() error) {
+	t.Helper()
+	errCh := make(chan error, 1)
+	go func() { errCh <- run() }()
+
+	var runErr error
+	done := false
+	stop = func() error {
+		if !done {
+			app.Shutdown()
+			runErr, done = <-errCh, true
+		}
+		return runErr
+	}
+	t.Cleanup(func() { stop() })
+
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		select {
+		case err := <-errCh:
+			done, runErr = true, err
+			t.Fatalf("Run returned early: %v", err)
+		default:
+		}
+		if ready() == nil {
+			return stop
+		}
+	}
+	t.Fatal("server did not start")
+	return nil
+}
+
+func getReport(t *testing.T, url string) (int, health.Report) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var report health.Report
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatalf("decode %s: %v", url, err)
+	}
+	return resp.StatusCode, report
+}
+
+func TestHealthProbes(t *testing.T) {
+	loadTestConfig(t)
+	mqDown := errors.New("connection refused")
+	var mqFailing atomic.Bool
+	addr := freeAddr(t)
+	startApp(t, nomoyu.New().WithHealthCheck(
+		health.New("cache", func(context.Context) error { return nil }),
+		health.New("mq", func(context.Context) error {
+			if mqFailing.Load() {
+				return mqDown
+			}
+			return nil
+		}),
+	), addr)
+
+	code, report := getReport(t, "http://"+addr+"/healthz")
+	if code != http.StatusOK || report.Status != health.StatusUp || len(report.Components) != 0 {
+		t.Fatalf("healthz: %d %+v", code, report)
+	}
+	code, report = getReport(t, "http://"+addr+"/readyz")
+	if code != http.StatusOK || report.Status != health.StatusUp || len(report.Components) != 2 {
+		t.Fatalf("readyz: %d %+v", code, report)
+	}
+
+	// 存活探针不执行检查项，就绪探针报告失败的组件
+	mqFailing.Store(true)
+	if code, _ := getReport(t, "http://"+addr+"/healthz"); code != http.StatusOK {
+		t.Fatalf("healthz with failing checker: %d", code)
+	}
+	code, report = getReport(t, "http://"+addr+"/readyz")
+	if code != http.StatusServiceUnavailable || report.Status != health.StatusDown {
+		t.Fatalf("readyz with failing checker: %d %+v", code, report)
+	}
+	cache, mq := report.Components[0], report.Components[1]
+	if cache.Name != "cache" || cache.Status != health.StatusUp || cache.Error != "" {
+		t.Errorf("cache: %+v", cache)
+	}
+	if mq.Name != "mq" || mq.Status != health.StatusDown || mq.Error != mqDown.Error() {
+		t.Errorf("mq: %+v", mq)
+	}
+	for _, c := range report.Components {
+		if _, err := time.ParseDuration(c.Latency); err != nil {
+			t.Errorf("%s latency %q: %v", c.Name, c.Latency, err)
+		}
+	}
+}
+
+func TestReadinessFailsDuringDrain(t *testing.T) {
+	loadTestConfig(t)
+	addr := freeAddr(t)
+	app := nomoyu.New().
+		WithDrainDelay(300 * time.Millisecond).
+		WithRoute(nomoyu.NewGroup("/api").GET("/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") }))
+	stop := startApp(t, app, addr)
+
+	stopped := make(chan error, 1)
+	start := time.Now()
+	go func() { stopped <- stop() }()
+	time.Sleep(100 * time.Millisecond)
+
+	// 摘流量等待期间：就绪探针失败，业务请求与存活探针照常处理
+	code, report := getReport(t, "http://"+addr+"/readyz")
+	if code != http.StatusServiceUnavailable || report.Status != health.StatusDown ||
+		len(report.Components) != 1 || report.Components[0].Name != "server" {
+		t.Fatalf("readyz while draining: %d %+v", code, report)
+	}
+	if code, _ := getReport(t, "http://"+addr+"/healthz"); code != http.StatusOK {
+		t.Fatalf("healthz while draining: %d", code)
+	}
+	resp, err := http.Get("http://" + addr + "/api/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("request while draining: %d", resp.StatusCode)
+	}
+
+	if err := <-stopped; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Fatalf("shutdown took %s, want >= drain delay", elapsed)
+	}
+	if _, err := http.Get("http://" + addr + "/healthz"); err == nil {
+		t.Fatal("server still accepting connections after shutdown")
+	}
+}
@@ -0,0 +1,131 @@
+package nomoyu
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+)
+
+// 监听 socket 名称，平滑重启时按名称交接给新进程
+const (
+	listenerMain  = "main"
+	listenerAdmin = "admin"
+
+	unixScheme = "unix://"
+
+	// 交接后等待已接受的连接读到请求的最长时间
+	handoffAcceptWait = time.Second
+)
+
+type namedListener struct {
+	name string
+	ln   net.Listener
+}
+
+// WithGracefulRestart 开启平滑重启（仅类 Unix 系统）：收到 SIGHUP/SIGUSR2 时
+// 以相同参数启动新进程并交接监听 socket，新进程就绪后当前进程走优雅停机流程
+func (a *App) WithGracefulRestart() *App {
+	a.gracefulRestart = true
+	return a
+}
+
+func (a *App) gracefulRestartEnabled() bool {
+	return a.gracefulRestart || config.Current().Server.GracefulRestart
+}
+
+// listen 获取监听 socket，优先级：父进程交接（平滑重启）> systemd socket 激活 > 按地址新建
+func (a *App) listen(name, addr string) (net.Listener, error) {
+	ln, ok, err := inheritedListener(name)
+	if err == nil && !ok {
+		ln, ok, err = systemdListener(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if ln, err = listenAddr(addr, a.serverOption.SocketMode); err != nil {
+			return nil, err
+		}
+	}
+	a.listeners = append(a.listeners, namedListener{name: name, ln: ln})
+	return ln, nil
+}
+
+// listenAddr 支持 host:port 与 unix:///path.sock
+func listenAddr(addr string, mode os.FileMode) (net.Listener, error) {
+	path, ok := strings.CutPrefix(addr, unixScheme)
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			_ = ln.Close()
+			return nil, fmt.Errorf("chmod %s: %w", path, err)
+		}
+	}
+	// 进程退出关闭 listener 时自动删除 socket 文件
+	return ln, nil
+}
+
+// removeStaleSocket 清理上次异常退出遗留的 socket 文件；仍有进程在监听时报错
+func removeStaleSocket(path string) error {
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if fi.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("%s is in use by another process", path)
+	}
+	return os.Remove(path)
+}
+
+// trackConnState 作为 http.Server.ConnState，记录已接受但还没读到请求的连接
+func (a *App) trackConnState(c net.Conn, st http.ConnState) {
+	a.connMu.Lock()
+	defer a.connMu.Unlock()
+	if st != http.StateNew {
+		delete(a.pendingConns, c)
+		return
+	}
+	if a.pendingConns == nil {
+		a.pendingConns = map[net.Conn]struct{}{}
+	}
+	a.pendingConns[c] = struct{}{}
+}
+
+// stopAccepting 交接后关闭当前进程的监听 socket（新进程继续 accept），并等待已接受的连接读到请求：
+// http.Server.Shutdown 会直接关闭还没读到请求的连接，客户端只会收到 EOF
+func (a *App) stopAccepting(timeout time.Duration) {
+	for _, l := range a.listeners {
+		_ = l.ln.Close()
+	}
+	for deadline := time.Now().Add(timeout); time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+		a.connMu.Lock()
+		n := len(a.pendingConns)
+		a.connMu.Unlock()
+		if n == 0 {
+			return
+		}
+	}
+}
@@ -0,0 +1,173 @@
+//go:build !windows
+
+package nomoyu_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nomoyu/go-gin-framework/nomoyu"
+)
+
+// unixGet 通过 unix socket 请求 path
+func unixGet(sock, path string) (*http.Response, error) {
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", sock)
+		},
+	}}
+	defer client.CloseIdleConnections()
+	resp, err := client.Get("http://unix" + path)
+	if err == nil {
+		resp.Body.Close()
+	}
+	return resp, err
+}
+
+// staleSocket 留下一个没有进程监听的 socket 文件（模拟异常退出）
+func staleSocket(t *testing.T, path string) {
+	t.Helper()
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	ln.Close()
+}
+
+func TestUnixSocket(t *testing.T) {
+	loadTestConfig(t)
+	sock := filepath.Join(t.TempDir(), "app.sock")
+	staleSocket(t, sock)
+
+	app := nomoyu.New().WithServer(nomoyu.ServerOption{SocketMode: 0o660})
+	stop := runApp(t, app, func() error { return app.Run("unix://" + sock) }, func() error {
+		_, err := unixGet(sock, "/healthz")
+		return err
+	})
+
+	// 启动时清理遗留的 socket 文件，并按 SocketMode 设置权限
+	fi, err := os.Stat(sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode()&os.ModeSocket == 0 || fi.Mode().Perm() != 0o660 {
+		t.Fatalf("socket mode = %v, want socket 0660", fi.Mode())
+	}
+	if resp, err := unixGet(sock, "/healthz"); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /healthz over unix socket: %v", err)
+	}
+
+	// 停机后删除 socket 文件
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(sock); !os.IsNotExist(err) {
+		t.Fatalf("socket file left after shutdown: %v", err)
+	}
+}
+
+func TestUnixSocketRefusesLiveOrNonSocket(t *testing.T) {
+	loadTestConfig(t)
+	dir := t.TempDir()
+
+	live := filepath.Join(dir, "live.sock")
+	ln, err := net.Listen("unix", live)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	if err := nomoyu.New().Run("unix://" + live); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Fatalf("Run on a socket in use: %v", err)
+	}
+
+	regular := filepath.Join(dir, "regular.sock")
+	if err := os.WriteFile(regular, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := nomoyu.New().Run("unix://" + regular); err == nil || !strings.Contains(err.Error(), "not a socket") {
+		t.Fatalf("Run on a regular file: %v", err)
+	}
+	if _, err := os.Stat(regular); err != nil {
+		t.Fatalf("regular file removed: %v", err)
+	}
+}
+
+func TestRunListener(t *testing.T) {
+	loadTestConfig(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	app := nomoyu.New()
+	stop := runApp(t, app, func() error { return app.RunListener(ln) }, func() error {
+		resp, err := http.Get("http://" + addr + "/healthz")
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	})
+
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+	if conn, err := net.Dial("tcp", addr); err == nil {
+		conn.Close()
+		t.Fatal("listener still open after shutdown")
+	}
+}
+
+func TestSystemdSocketActivation(t *testing.T) {
+	cases := []struct {
+		name  string
+		env   []string
+		admin bool // 第 2 个 fd 作为管理端口
+	}{
+		{name: "named", env: []string{"LISTEN_FDS=2", "LISTEN_FDNAMES=admin:main"}},
+		{name: "unnamed", env: []string{"LISTEN_FDS=2"}, admin: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			first, firstFile := tcpListenerFile(t)
+			second, secondFile := tcpListenerFile(t)
+			mainAddr, adminAddr := second, first
+			if tc.admin {
+				mainAddr, adminAddr = first, second
+			}
+			ownAddr := freeAddr(t)
+			h := startHelper(t, append(tc.env,
+				"NOMOYU_TEST_LISTEN_PID=self",
+				"NOMOYU_TEST_ADDR="+ownAddr,
+				"NOMOYU_TEST_ADMIN="+freeAddr(t),
+			), firstFile, secondFile)
+
+			waitPid(t, h, mainAddr, func(pid int) bool { return pid == h.cmd.Process.Pid })
+			resp, err := http.Get("http://" + adminAddr + "/log/level")
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("admin via activated socket: %d", resp.StatusCode)
+			}
+			if _, err := pidAt(ownAddr); err == nil {
+				t.Fatalf("helper also listens on %s", ownAddr)
+			}
+		})
+	}
+}
+
+func TestSystemdSocketActivationPIDMismatch(t *testing.T) {
+	_, f := tcpListenerFile(t)
+	ownAddr := freeAddr(t)
+	// LISTEN_PID 不是当前进程（如由父进程继承）时忽略 LISTEN_FDS，按地址自行监听
+	h := startHelper(t, []string{"LISTEN_FDS=1", "LISTEN_PID=1", "NOMOYU_TEST_ADDR=" + ownAddr}, f)
+	waitPid(t, h, ownAddr, func(pid int) bool { return pid == h.cmd.Process.Pid })
+}
@@ -17,29 +17,22 @@ func (a *App) WithLog(path string, level string) *App {
 		Level:    level,
 		FromUser: true,
 	}
-	logger.InitLoggerWithConfig(path, level)
 	return a
 }
 
-// ✅ 自动从配置初始化（仅当未设置 WithLog 时调用）
+// ✅ 初始化日志：WithLog 优先，否则从配置读取
 func initLogFromConfigIfPresent(app *App) {
 	if app.logOption != nil && app.logOption.FromUser {
-		return // 已由用户设置，跳过
+		logger.InitLoggerWithConfig(app.logOption.Path, app.logOption.Level)
+		return
 	}
 
-	conf := config.Conf.Log
-	if conf.Path == "" {
-		conf.Path = "./logs"
-	}
-	if conf.Level == "" {
-		conf.Level = "info"
-	}
-	if conf.Path != "" && conf.Level != "" {
-		app.logOption = &LogOption{
-			Path:     conf.Path,
-			Level:    conf.Level,
-			FromUser: false,
-		}
-		logger.InitLoggerWithConfig(conf.Path, conf.Level)
+	// 默认 ./logs、info（config.Log 的 default 标签）
+	conf := config.Current().Log
+	app.logOption = &LogOption{
+		Path:     conf.Path,
+		Level:    conf.Level,
+		FromUser: false,
 	}
+	logger.InitLoggerWithConfig(conf.Path, conf.Level)
 }
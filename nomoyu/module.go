@@ -1,8 +1,151 @@
 package nomoyu
 
-import "github.com/gin-gonic/gin"
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
 
 // Module 所有模块必须实现该接口，注入路由/中间件/服务等
 type Module interface {
 	Register(r *gin.Engine)
 }
+
+// NamedModule 可选：模块名，用于依赖声明与日志（未实现时使用类型名）
+type NamedModule interface {
+	Name() string
+}
+
+// DependentModule 可选：声明依赖的模块名，依赖会先于当前模块 Init/Start
+type DependentModule interface {
+	DependsOn() []string
+}
+
+// InitModule 可选：在路由注册之前初始化资源（打开连接、预热缓存等）
+type InitModule interface {
+	Init(ctx context.Context, app *App) error
+}
+
+// StartModule 可选：HTTP 服务开始监听之前启动后台任务
+type StartModule interface {
+	Start(ctx context.Context) error
+}
+
+// StopModule 可选：Init 成功后登记为停机钩子，优雅停机或 Build 失败时按初始化顺序的逆序调用（模块可能未 Start）
+type StopModule interface {
+	Stop(ctx context.Context) error
+}
+
+// ModuleName 返回模块名：优先 Name()，否则为类型名（如 *swagger.SwaggerModule）
+func ModuleName(m Module) string {
+	if n, ok := m.(NamedModule); ok && n.Name() != "" {
+		return n.Name()
+	}
+	return fmt.Sprintf("%T", m)
+}
+
+func (a *App) hasModule(name string) bool {
+	for _, m := range a.modules {
+		if ModuleName(m) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sortModules 按 DependsOn 做稳定拓扑排序：无依赖关系的模块保持注册顺序
+func sortModules(modules []Module) ([]Module, error) {
+	index := make(map[string]int, len(modules))
+	for i, m := range modules {
+		name := ModuleName(m)
+		if _, dup := index[name]; dup {
+			return nil, fmt.Errorf("duplicate module %q", name)
+		}
+		index[name] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(modules))
+	sorted := make([]Module, 0, len(modules))
+	var path []string
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("module dependency cycle: %s -> %s", strings.Join(path, " -> "), ModuleName(modules[i]))
+		}
+		state[i] = visiting
+		path = append(path, ModuleName(modules[i]))
+		if d, ok := modules[i].(DependentModule); ok {
+			for _, dep := range d.DependsOn() {
+				j, ok := index[dep]
+				if !ok {
+					return fmt.Errorf("module %q depends on unknown module %q", ModuleName(modules[i]), dep)
+				}
+				if err := visit(j); err != nil {
+					return err
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = done
+		sorted = append(sorted, modules[i])
+		return nil
+	}
+
+	for i := range modules {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// initModules 排序后依次调用 Init；每个模块 Init 成功（或未实现 Init）后即用 OnShutdown 登记它的 Stop，
+// 停机时与其他钩子一起倒序执行：模块 Init 中登记的钩子先于它所依赖的模块 Stop
+func (a *App) initModules(ctx context.Context) error {
+	sorted, err := sortModules(a.modules)
+	if err != nil {
+		return err
+	}
+	a.modules = sorted
+
+	for _, m := range a.modules {
+		if im, ok := m.(InitModule); ok {
+			if err := im.Init(ctx, a); err != nil {
+				return fmt.Errorf("init module %s: %w", ModuleName(m), err)
+			}
+		}
+		if sm, ok := m.(StopModule); ok {
+			name := ModuleName(m)
+			a.OnShutdown(func(ctx context.Context) error {
+				if err := sm.Stop(ctx); err != nil {
+					return fmt.Errorf("stop module %s: %w", name, err)
+				}
+				return nil
+			})
+		}
+	}
+	return nil
+}
+
+// startModules 按依赖顺序启动模块；失败时由调用方停机，已初始化的模块都会被停止
+func (a *App) startModules(ctx context.Context) error {
+	for _, m := range a.modules {
+		if sm, ok := m.(StartModule); ok {
+			if err := sm.Start(ctx); err != nil {
+				return fmt.Errorf("start module %s: %w", ModuleName(m), err)
+			}
+		}
+	}
+	return nil
+}
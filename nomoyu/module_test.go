@@ -0,0 +1,139 @@
+package nomoyu_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/nomoyu"
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+)
+
+// lifecycleModule 把生命周期调用记录到 events
+type lifecycleModule struct {
+	name     string
+	deps     []string
+	initErr  error
+	startErr error
+	hook     bool // Init 中用 OnShutdown 登记钩子
+	events   *[]string
+}
+
+func (m *lifecycleModule) Name() string           { return m.name }
+func (m *lifecycleModule) DependsOn() []string    { return m.deps }
+func (m *lifecycleModule) Register(r *gin.Engine) {}
+
+func (m *lifecycleModule) Init(ctx context.Context, app *nomoyu.App) error {
+	*m.events = append(*m.events, "init "+m.name)
+	if m.hook {
+		app.OnShutdown(func(ctx context.Context) error {
+			*m.events = append(*m.events, "hook "+m.name)
+			return nil
+		})
+	}
+	return m.initErr
+}
+
+func (m *lifecycleModule) Start(ctx context.Context) error {
+	*m.events = append(*m.events, "start "+m.name)
+	return m.startErr
+}
+
+func (m *lifecycleModule) Stop(ctx context.Context) error {
+	*m.events = append(*m.events, "stop "+m.name)
+	return nil
+}
+
+func loadTestConfig(t *testing.T) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	prev := config.Current()
+	t.Cleanup(func() { config.Set(prev) })
+	if err := config.LoadBytes([]byte(fmt.Sprintf("app: {name: m, env: test}\nlog: {level: error, path: %q}\n", t.TempDir()))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestModuleStopsAfterFailedInit(t *testing.T) {
+	loadTestConfig(t)
+	var events []string
+	err := nomoyu.New().
+		WithModule(&lifecycleModule{name: "c", deps: []string{"b"}, events: &events}).
+		WithModule(&lifecycleModule{name: "b", deps: []string{"a"}, initErr: errors.New("boom"), events: &events}).
+		WithModule(&lifecycleModule{name: "a", events: &events}).
+		Build()
+	if err == nil {
+		t.Fatal("expected init error")
+	}
+	want := []string{"init a", "init b", "stop a"}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+}
+
+func TestModuleStopsAfterFailedBuild(t *testing.T) {
+	loadTestConfig(t)
+	var events []string
+	err := nomoyu.New().
+		WithModule(&lifecycleModule{name: "a", events: &events}).
+		WithModule(&lifecycleModule{name: "b", deps: []string{"a"}, events: &events}).
+		WithVersioning("bogus").
+		Build()
+	if err == nil {
+		t.Fatal("expected versioning error")
+	}
+	want := []string{"init a", "init b", "stop b", "stop a"}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+}
+
+func TestModuleStopsAfterFailedStart(t *testing.T) {
+	loadTestConfig(t)
+	var events []string
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	err = nomoyu.New().
+		WithModule(&lifecycleModule{name: "a", events: &events}).
+		WithModule(&lifecycleModule{name: "b", startErr: errors.New("boom"), events: &events}).
+		WithModule(&lifecycleModule{name: "c", events: &events}).
+		RunListener(ln)
+	if err == nil {
+		t.Fatal("expected start error")
+	}
+	want := []string{"init a", "init b", "init c", "start a", "start b", "stop c", "stop b", "stop a"}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+}
+
+func TestModuleStopOrderWithHooks(t *testing.T) {
+	loadTestConfig(t)
+	var events []string
+	app := nomoyu.New().
+		OnShutdown(func(ctx context.Context) error {
+			events = append(events, "hook app")
+			return nil
+		}).
+		WithModule(&lifecycleModule{name: "b", deps: []string{"a"}, hook: true, events: &events}).
+		WithModule(&lifecycleModule{name: "a", events: &events})
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+	app.Shutdown()
+	app.Shutdown()
+
+	// b 的 Init 中登记的钩子可能还在使用 a，要先于 a 停止；Build 前登记的钩子最后执行
+	want := []string{"init a", "init b", "stop b", "hook b", "stop a", "hook app"}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+}
@@ -0,0 +1,276 @@
+// Package nomoyutest 提供进程内的 nomoyu 应用测试工具：内存配置、内存 sqlite、
+// 假 Redis、基于 httptest 的请求执行，以及 JWT 与响应结构断言辅助函数。
+package nomoyutest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/nomoyu"
+	"github.com/nomoyu/go-gin-framework/pkg/auth"
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+	"github.com/nomoyu/go-gin-framework/pkg/db"
+	"github.com/nomoyu/go-gin-framework/pkg/redisx"
+	"github.com/nomoyu/go-gin-framework/pkg/response"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultJWTSecret 未在配置中指定 auth.jwt.secret 时使用的密钥
+const DefaultJWTSecret = "nomoyutest-secret"
+
+var dbSeq int64
+
+type options struct {
+	yamls  []string
+	sqlite bool
+	redis  bool
+	setup  []func(app *nomoyu.App)
+}
+
+// Option 测试应用选项
+type Option func(*options)
+
+// WithConfig 追加 YAML 配置（覆盖默认测试配置中的同名键），多次调用时按顺序合并，后者覆盖前者
+func WithConfig(yamlText string) Option {
+	return func(o *options) { o.yamls = append(o.yamls, yamlText) }
+}
+
+// WithSQLite 使用独立的内存 sqlite 作为 pkg/db
+func WithSQLite() Option {
+	return func(o *options) { o.sqlite = true }
+}
+
+// WithFakeRedis 启动进程内的假 Redis（miniredis）作为 pkg/redisx
+func WithFakeRedis() Option {
+	return func(o *options) { o.redis = true }
+}
+
+// WithApp 在 Build 之前对应用做链式配置（WithRoute、WithModule、WithAuth 等）
+func WithApp(fn func(app *nomoyu.App)) Option {
+	return func(o *options) { o.setup = append(o.setup, fn) }
+}
+
+// App 已 Build 完成的测试应用
+type App struct {
+	*nomoyu.App
+	// Redis 开启 WithFakeRedis 时可直接操作/检查假 Redis 中的数据
+	Redis *miniredis.Miniredis
+
+	t testing.TB
+}
+
+// New 构建一个不监听端口的测试应用，测试结束时自动停机（停机钩子、模块 Stop），再释放 db / redis 并恢复全局配置
+func New(t testing.TB, opts ...Option) *App {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	h := &App{t: t}
+
+	base := map[string]any{
+		"app": map[string]any{"name": "nomoyutest", "env": "test"},
+		"log": map[string]any{"path": t.TempDir(), "level": "error"},
+	}
+	if o.sqlite {
+		n := atomic.AddInt64(&dbSeq, 1)
+		base["database"] = map[string]any{
+			"dialect": "sqlite",
+			"dbname":  fmt.Sprintf("file:nomoyutest_%d?mode=memory&cache=shared", n),
+		}
+	}
+	if o.redis {
+		mr, err := miniredis.Run()
+		if err != nil {
+			t.Fatalf("nomoyutest: start fake redis: %v", err)
+		}
+		h.Redis = mr
+		base["redis"] = map[string]any{"addr": mr.Addr()}
+	}
+	for _, text := range o.yamls {
+		var override map[string]any
+		if err := yaml.Unmarshal([]byte(text), &override); err != nil {
+			t.Fatalf("nomoyutest: parse config: %v", err)
+		}
+		mergeMap(base, override)
+	}
+
+	raw, err := yaml.Marshal(base)
+	if err != nil {
+		t.Fatalf("nomoyutest: marshal config: %v", err)
+	}
+	prevConf := config.Current()
+	if err := config.LoadBytes(raw); err != nil {
+		t.Fatalf("nomoyutest: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = db.Reset()
+		_ = redisx.Close()
+		if h.Redis != nil {
+			h.Redis.Close()
+		}
+		config.Set(prevConf)
+	})
+
+	h.App = nomoyu.New()
+	for _, fn := range o.setup {
+		fn(h.App)
+	}
+	if err := h.App.Build(); err != nil {
+		t.Fatalf("nomoyutest: build app: %v", err)
+	}
+	// Cleanup 倒序执行：先停机，模块 Stop 时 db / redis 仍可用
+	t.Cleanup(h.App.Shutdown)
+	return h
+}
+
+// Do 在业务端 Handler 上执行请求
+func (h *App) Do(req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+// GET 便捷方法
+func (h *App) GET(path string, opts ...RequestOption) *httptest.ResponseRecorder {
+	return h.Do(NewRequest(h.t, http.MethodGet, path, nil, opts...))
+}
+
+// POST 便捷方法，body 规则同 NewRequest
+func (h *App) POST(path string, body any, opts ...RequestOption) *httptest.ResponseRecorder {
+	return h.Do(NewRequest(h.t, http.MethodPost, path, body, opts...))
+}
+
+// MintJWT 使用配置中的 auth.jwt.secret（未配置时为 DefaultJWTSecret）签发 token
+func (h *App) MintJWT(id, name string, roles ...string) string {
+	h.t.Helper()
+	secret := config.Current().Auth.JWT.Secret
+	if secret == "" {
+		secret = DefaultJWTSecret
+	}
+	token, err := auth.GenerateJWT(id, name, roles, secret, time.Hour)
+	if err != nil {
+		h.t.Fatalf("nomoyutest: mint jwt: %v", err)
+	}
+	return token
+}
+
+// RequestOption 请求选项
+type RequestOption func(req *http.Request)
+
+// WithHeader 设置请求头
+func WithHeader(key, value string) RequestOption {
+	return func(req *http.Request) { req.Header.Set(key, value) }
+}
+
+// WithBearer 设置 Authorization: Bearer <token>
+func WithBearer(token string) RequestOption {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+// NewRequest 构造请求：body 为 string/[]byte 时原样发送，其他类型编码为 JSON
+func NewRequest(t testing.TB, method, path string, body any, opts ...RequestOption) *http.Request {
+	t.Helper()
+	var r io.Reader
+	isJSON := false
+	switch b := body.(type) {
+	case nil:
+	case string:
+		r = bytes.NewBufferString(b)
+	case []byte:
+		r = bytes.NewBuffer(b)
+	default:
+		raw, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("nomoyutest: marshal body: %v", err)
+		}
+		r = bytes.NewBuffer(raw)
+		isJSON = true
+	}
+
+	req := httptest.NewRequest(method, path, r)
+	if isJSON {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for _, opt := range opts {
+		opt(req)
+	}
+	return req
+}
+
+// DecodeResponse 解析统一响应结构 response.Response；out 非 nil 时把 data 解码到 out
+func DecodeResponse(t testing.TB, rec *httptest.ResponseRecorder, out any) response.Response {
+	t.Helper()
+	var env struct {
+		Code int             `json:"code"`
+		Msg  string          `json:"msg"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &env); err != nil {
+		t.Fatalf("nomoyutest: response is not a response.Response envelope (status=%d): %v\n%s", rec.Code, err, rec.Body.String())
+	}
+
+	res := response.Response{Code: env.Code, Msg: env.Msg}
+	if len(env.Data) > 0 {
+		var data any
+		_ = json.Unmarshal(env.Data, &data)
+		res.Data = data
+		if out != nil {
+			if err := json.Unmarshal(env.Data, out); err != nil {
+				t.Fatalf("nomoyutest: decode data: %v", err)
+			}
+		}
+	}
+	return res
+}
+
+// AssertSuccess 断言 HTTP 200 且 code == response.CodeSuccess，返回解析后的响应
+func AssertSuccess(t testing.TB, rec *httptest.ResponseRecorder, out any) response.Response {
+	t.Helper()
+	return AssertCode(t, rec, response.CodeSuccess, out)
+}
+
+// AssertCode 断言 HTTP 200 且业务 code 等于期望值（如 errorcode.InvalidParams.Code）
+func AssertCode(t testing.TB, rec *httptest.ResponseRecorder, code int, out any) response.Response {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("nomoyutest: expected http status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	res := DecodeResponse(t, rec, out)
+	if res.Code != code {
+		t.Fatalf("nomoyutest: expected code %d, got %d (msg=%q)", code, res.Code, res.Msg)
+	}
+	return res
+}
+
+// AssertStatus 断言 HTTP 状态码（如 401 页面、404 页面、探针 503）
+func AssertStatus(t testing.TB, rec *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("nomoyutest: expected http status %d, got %d: %s", status, rec.Code, rec.Body.String())
+	}
+}
+
+func mergeMap(dst, src map[string]any) {
+	for k, v := range src {
+		if sm, ok := v.(map[string]any); ok {
+			if dm, ok := dst[k].(map[string]any); ok {
+				mergeMap(dm, sm)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
@@ -0,0 +1,123 @@
+package nomoyutest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/nomoyu"
+	"github.com/nomoyu/go-gin-framework/nomoyu/nomoyutest"
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+	"github.com/nomoyu/go-gin-framework/pkg/db"
+	"github.com/nomoyu/go-gin-framework/pkg/errorcode"
+	"github.com/nomoyu/go-gin-framework/pkg/redisx"
+	"github.com/nomoyu/go-gin-framework/pkg/response"
+)
+
+func TestNew(t *testing.T) {
+	prev := config.Current()
+	// 每个测试应用使用独立的 sqlite 与假 Redis
+	for _, name := range []string{"first", "second"} {
+		t.Run(name, func(t *testing.T) {
+			app := nomoyutest.New(t, nomoyutest.WithSQLite(), nomoyutest.WithFakeRedis(),
+				nomoyutest.WithConfig("auth:\n  enabled: true\n  mode: jwt\n  jwt:\n    secret: abc\n"),
+				nomoyutest.WithApp(func(a *nomoyu.App) {
+					a.WithRoute(nomoyu.NewGroup("/api").
+						GET("/ping", func(c *gin.Context) {
+							var n int
+							if err := db.DB().Raw("select count(*) from sqlite_master").Scan(&n).Error; err != nil {
+								response.FailWithCode(c, errorcode.ServerError)
+								return
+							}
+							db.DB().Exec("create table t (id integer)")
+							rc, err := redisx.Client()
+							if err != nil {
+								response.FailWithCode(c, errorcode.ServerError)
+								return
+							}
+							rc.Set(c, "k", "v", 0)
+							response.Success(c, gin.H{"tables": n})
+						}).
+						GET("/bad", func(c *gin.Context) { response.FailWithCode(c, errorcode.InvalidParams) }))
+					a.WithRoute(nomoyu.NewGroup("/sec").RequireAuth().GET("/me", func(c *gin.Context) { response.Success(c, "ok") }))
+				}))
+
+			var out struct{ Tables int }
+			nomoyutest.AssertSuccess(t, app.GET("/api/ping"), &out)
+			if out.Tables != 0 {
+				t.Fatalf("database shared between test apps: %d tables", out.Tables)
+			}
+			if v, _ := app.Redis.Get("k"); v != "v" {
+				t.Fatalf("redis k = %q", v)
+			}
+			nomoyutest.AssertCode(t, app.GET("/api/bad"), errorcode.InvalidParams.Code, nil)
+			nomoyutest.AssertStatus(t, app.GET("/sec/me"), http.StatusUnauthorized)
+			nomoyutest.AssertSuccess(t, app.GET("/sec/me", nomoyutest.WithBearer(app.MintJWT("1", "bob"))), nil)
+			nomoyutest.AssertStatus(t, app.GET("/readyz"), http.StatusOK)
+			if config.Current().Auth.JWT.Secret != "abc" {
+				t.Fatal("WithConfig not applied")
+			}
+		})
+	}
+	if config.Current() != prev {
+		t.Fatal("config not restored after the test")
+	}
+}
+
+func TestDecodeResponse(t *testing.T) {
+	app := nomoyutest.New(t, nomoyutest.WithApp(func(a *nomoyu.App) {
+		a.WithRoute(nomoyu.NewGroup("/echo").POST("", func(c *gin.Context) {
+			var body map[string]any
+			_ = c.ShouldBindJSON(&body)
+			body["token"] = c.GetHeader("X-Token")
+			response.Success(c, body)
+		}))
+	}))
+	var out struct {
+		Name  string `json:"name"`
+		Token string `json:"token"`
+	}
+	res := nomoyutest.AssertSuccess(t, app.POST("/echo", map[string]any{"name": "bob"}, nomoyutest.WithHeader("X-Token", "tk")), &out)
+	if out.Name != "bob" || out.Token != "tk" || res.Msg == "" {
+		t.Fatalf("decoded %+v %+v", out, res)
+	}
+}
+
+// stopRecorder 记录 Stop 是否执行，以及执行时 db 是否仍可用
+type stopRecorder struct {
+	stopped bool
+	dbOK    bool
+}
+
+func (m *stopRecorder) Name() string                            { return "recorder" }
+func (m *stopRecorder) Register(*gin.Engine)                    {}
+func (m *stopRecorder) Init(context.Context, *nomoyu.App) error { return nil }
+func (m *stopRecorder) Stop(context.Context) error {
+	m.stopped = true
+	m.dbOK = db.DB().Exec("select 1").Error == nil
+	return nil
+}
+
+func TestCleanupStopsModules(t *testing.T) {
+	m := &stopRecorder{}
+	t.Run("app", func(t *testing.T) {
+		nomoyutest.New(t, nomoyutest.WithSQLite(), nomoyutest.WithApp(func(a *nomoyu.App) { a.WithModule(m) }))
+		if m.stopped {
+			t.Fatal("module stopped before the test ended")
+		}
+	})
+	if !m.stopped || !m.dbOK {
+		t.Fatalf("after cleanup: stopped=%v, db available in Stop=%v", m.stopped, m.dbOK)
+	}
+}
+
+func TestWithConfigMerges(t *testing.T) {
+	nomoyutest.New(t,
+		nomoyutest.WithConfig("auth:\n  jwt:\n    secret: abc\n"),
+		nomoyutest.WithConfig("auth:\n  enabled: true\n  mode: jwt\n"),
+	)
+	if conf := config.Current().Auth; !conf.Enabled || conf.JWT.Secret != "abc" {
+		t.Fatalf("auth = %+v", conf)
+	}
+}
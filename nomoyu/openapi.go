@@ -0,0 +1,323 @@
+package nomoyu
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/internal/auth"
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+	"github.com/nomoyu/go-gin-framework/pkg/errorcode"
+	"github.com/nomoyu/go-gin-framework/pkg/openapi"
+)
+
+const (
+	openapiPath = "/openapi.json"
+	docsPath    = "/docs"
+	redocPath   = "/redoc"
+
+	envelopeSchema = "Response"
+	securityName   = "bearerAuth"
+)
+
+type OpenAPIOption struct {
+	Title       string
+	Description string
+	Servers     []string
+	// SecurityScheme 自定义认证方式的描述，默认按 Bearer 处理
+	SecurityScheme *openapi.SecurityScheme
+	FromUser       bool
+}
+
+// WithOpenAPI 根据已注册的 RouteGroup 在运行时生成 OpenAPI 3.1 文档（优先级高于配置 openapi.enabled）
+func (a *App) WithOpenAPI(opts ...func(o *OpenAPIOption)) *App {
+	opt := &OpenAPIOption{FromUser: true}
+	for _, fn := range opts {
+		fn(opt)
+	}
+	a.openapiOption = opt
+	return a
+}
+
+// initOpenAPIFromConfigIfPresent 在 Build() 中自动调用（WithOpenAPI 优先，其次配置文件）
+func initOpenAPIFromConfigIfPresent(app *App) {
+	conf := config.Current().OpenAPI
+	if app.openapiOption == nil || !app.openapiOption.FromUser {
+		if !conf.Enabled {
+			return
+		}
+		app.openapiOption = &OpenAPIOption{FromUser: false}
+	}
+	opt := app.openapiOption
+	opt.Title = firstNonZero(opt.Title, conf.Title, config.Current().App.Name, "nomoyu-go")
+	opt.Description = firstNonZero(opt.Description, conf.Description)
+	if len(opt.Servers) == 0 {
+		opt.Servers = conf.Servers
+	}
+	app.modules = append(app.modules, &openapiModule{app: app, opt: opt})
+}
+
+// openapiModule 提供 /openapi.json、Swagger UI 与 Redoc；文档在第一次请求时生成（此时路由已全部注册）
+type openapiModule struct {
+	app  *App
+	opt  *OpenAPIOption
+	once sync.Once
+	doc  *openapi.Document
+}
+
+func (m *openapiModule) Name() string {
+	return "openapi"
+}
+
+func (m *openapiModule) AdminOnly() bool {
+	return true
+}
+
+func (m *openapiModule) Register(r *gin.Engine) {
+	r.GET(openapiPath, func(c *gin.Context) {
+		m.once.Do(func() {
+			m.doc = m.app.buildOpenAPI(m.opt)
+		})
+		c.JSON(http.StatusOK, m.doc)
+	})
+	r.GET(docsPath, func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(fmt.Sprintf(swaggerUIPage, html.EscapeString(m.opt.Title), openapiPath)))
+	})
+	r.GET(redocPath, func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(fmt.Sprintf(redocPage, html.EscapeString(m.opt.Title), openapiPath)))
+	})
+}
+
+// buildOpenAPI 只收录通过 RouteGroup 注册的业务路由（不含探针、运维接口与模块自行注册的路由）
+func (a *App) buildOpenAPI(opt *OpenAPIOption) *openapi.Document {
+	doc := openapi.New(openapi.Info{
+		Title:       opt.Title,
+		Version:     firstNonZero(config.Current().App.Version, "dev"),
+		Description: strings.TrimLeft(opt.Description+errorCodeTable(), "\n"),
+	})
+	for _, s := range opt.Servers {
+		doc.Servers = append(doc.Servers, openapi.Server{URL: s})
+	}
+	gen := openapi.NewGenerator(doc)
+
+	doc.Components.Schemas[envelopeSchema] = &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"code": {Type: "integer", Description: "业务码，0 表示成功，其余见错误码表"},
+			"msg":  {Type: "string"},
+			"data": {},
+		},
+		Required: []string{"code", "msg"},
+	}
+	if a.authOption != nil {
+		doc.Components.SecuritySchemes[securityName] = securityScheme(a.authOption.Strategy, opt)
+	}
+
+	// 按请求头/Accept 声明版本时，客户端请求的是不带版本段的路径，同一路径的各版本合并为一个操作
+	versioned := map[string][]RouteInfo{}
+	var keys []string
+	for _, r := range a.Routes() {
+		if r.Admin || r.Group == "" || r.Protocol != "" {
+			continue
+		}
+		if r.PublicPath != "" {
+			key := routeKey(r.Method, r.PublicPath)
+			if _, ok := versioned[key]; !ok {
+				keys = append(keys, key)
+			}
+			versioned[key] = append(versioned[key], r)
+			continue
+		}
+		doc.AddOperation(r.Method, r.Path, operation(gen, r))
+	}
+	for _, key := range keys {
+		routes := versioned[key]
+		doc.AddOperation(routes[0].Method, routes[0].PublicPath, a.versionedOperation(gen, routes))
+	}
+	return doc
+}
+
+// versionedOperation 以未声明版本时命中的版本（默认版本，否则最新版本）描述操作，并注明可选版本：
+// header 方式增加版本请求头参数，accept 方式按 application/json; version=N 给出各版本的响应
+func (a *App) versionedOperation(gen *openapi.Generator, routes []RouteInfo) *openapi.Operation {
+	opt := a.versioningOption
+	sort.Slice(routes, func(i, j int) bool { return versionLess(routes[i].Version, routes[j].Version) })
+	primary := routes[len(routes)-1]
+	for _, r := range routes {
+		if opt.Default != "" && normalizeVersion(r.Version) == normalizeVersion(opt.Default) {
+			primary = r
+		}
+	}
+
+	public := func(r RouteInfo) RouteInfo {
+		r.Path = r.PublicPath
+		return r
+	}
+	op := operation(gen, public(primary))
+	switch opt.Strategy {
+	case VersionByHeader:
+		versions := make([]any, len(routes))
+		for i, r := range routes {
+			versions[i] = r.Version
+		}
+		op.Parameters = append(op.Parameters, openapi.Parameter{
+			Name:        opt.Header,
+			In:          "header",
+			Description: fmt.Sprintf("API 版本，未声明时为 %s；本文档描述的是该版本", primary.Version),
+			Schema:      &openapi.Schema{Type: "string", Enum: versions, Default: primary.Version},
+		})
+	case VersionByAccept:
+		ok := op.Responses["200"]
+		for _, r := range routes {
+			mt := fmt.Sprintf("application/json; version=%s", normalizeVersion(r.Version))
+			ok.Content[mt] = operation(gen, public(r)).Responses["200"].Content["application/json"]
+		}
+	}
+	return op
+}
+
+func operation(gen *openapi.Generator, r RouteInfo) *openapi.Operation {
+	op := &openapi.Operation{
+		OperationID: operationID(r.Method, r.Path),
+		Summary:     r.Summary,
+		Description: r.Description,
+		Tags:        r.Tags,
+		Responses:   map[string]*openapi.Response{},
+		Deprecated:  r.Deprecated,
+	}
+	if len(op.Tags) == 0 {
+		if tag := groupTag(r.Group); tag != "" {
+			op.Tags = []string{tag}
+		}
+	}
+
+	var data *openapi.Schema
+	if r.Request != nil {
+		op.Parameters = gen.Parameters(r.Request)
+		if methodHasBody(r.Method) {
+			if body := gen.Body(r.Request); body != nil {
+				op.RequestBody = &openapi.RequestBody{
+					Required: true,
+					Content:  map[string]openapi.MediaType{"application/json": {Schema: body}},
+				}
+			}
+		}
+	}
+	if r.Response != nil && r.Response != reflect.TypeOf(struct{}{}) {
+		data = gen.Schema(r.Response)
+	}
+	// 未在请求类型中声明的路径参数按字符串补齐
+	for _, name := range openapi.PathParams(r.Path) {
+		if !hasParam(op.Parameters, name, "path") {
+			op.Parameters = append(op.Parameters, openapi.Parameter{
+				Name: name, In: "path", Required: true, Schema: &openapi.Schema{Type: "string"},
+			})
+		}
+	}
+
+	success := openapi.Ref(envelopeSchema)
+	if data != nil {
+		success = &openapi.Schema{AllOf: []*openapi.Schema{
+			openapi.Ref(envelopeSchema),
+			{Type: "object", Properties: map[string]*openapi.Schema{"data": data}},
+		}}
+	}
+	op.Responses["200"] = &openapi.Response{
+		Description: "统一响应结构，code 非 0 时表示业务错误",
+		Content:     map[string]openapi.MediaType{"application/json": {Schema: success}},
+	}
+	if r.RequireAuth {
+		op.Security = []map[string][]string{{securityName: {}}}
+		op.Responses["401"] = &openapi.Response{Description: "未认证（返回 HTML 页面）"}
+	}
+	return op
+}
+
+func securityScheme(strategy auth.AuthStrategy, opt *OpenAPIOption) *openapi.SecurityScheme {
+	if opt.SecurityScheme != nil {
+		return opt.SecurityScheme
+	}
+	if _, ok := strategy.(*auth.JWTStrategy); ok {
+		return &openapi.SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"}
+	}
+	return &openapi.SecurityScheme{Type: "http", Scheme: "bearer"}
+}
+
+// errorCodeTable 把已登记的错误码渲染为 markdown 表格附在文档说明后
+func errorCodeTable() string {
+	var b strings.Builder
+	b.WriteString("\n\n### 错误码\n\n| code | msg |\n|------|-----|\n")
+	for _, ec := range errorcode.All() {
+		fmt.Fprintf(&b, "| %d | %s |\n", ec.Code, ec.Msg)
+	}
+	return b.String()
+}
+
+// operationID 如 GET /api/users/:id -> get_api_users_id
+func operationID(method, path string) string {
+	id := strings.NewReplacer("/", "_", ":", "", "*", "", "-", "_", ".", "_").Replace(path)
+	return strings.ToLower(method) + strings.TrimRight(id, "_")
+}
+
+// groupTag 取分组路径最后一个非参数段，如 /api/v1/users -> users
+func groupTag(group string) string {
+	segs := strings.Split(strings.Trim(group, "/"), "/")
+	for i := len(segs) - 1; i >= 0; i-- {
+		if s := segs[i]; s != "" && !strings.HasPrefix(s, ":") && !strings.HasPrefix(s, "*") {
+			return s
+		}
+	}
+	return ""
+}
+
+func hasParam(params []openapi.Parameter, name, in string) bool {
+	for _, p := range params {
+		if p.Name == name && p.In == in {
+			return true
+		}
+	}
+	return false
+}
+
+func methodHasBody(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions, http.MethodTrace:
+		return false
+	}
+	return true
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>%[1]s</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "%[2]s", dom_id: "#swagger-ui", deepLinking: true });
+  </script>
+</body>
+</html>
+`
+
+const redocPage = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>%[1]s</title>
+</head>
+<body>
+  <redoc spec-url="%[2]s"></redoc>
+  <script src="https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"></script>
+</body>
+</html>
+`
@@ -0,0 +1,216 @@
+package nomoyu_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/nomoyu"
+	"github.com/nomoyu/go-gin-framework/nomoyu/nomoyutest"
+)
+
+type oasBase struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type oasUser struct {
+	oasBase
+	ID   int64    `json:"id"`
+	Name string   `json:"name" binding:"required,min=2,max=32"`
+	Role string   `json:"role" binding:"oneof=admin user"`
+	Boss *oasUser `json:"boss,omitempty"`
+	Tags []string `json:"tags"`
+}
+
+type oasGetReq struct {
+	ID     int64  `uri:"id" binding:"required,min=1"`
+	Fields string `form:"fields"`
+	Tenant string `header:"X-Tenant"`
+}
+
+type oasUpdateReq struct {
+	ID    int64  `uri:"id"`
+	Name  string `json:"name" binding:"required"`
+	Email string `json:"email" binding:"omitempty,email"`
+}
+
+type oasPage[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total"`
+}
+
+type oasListReq struct {
+	Page int `form:"page" binding:"gte=1"`
+}
+
+func oasGet(ctx context.Context, r *oasGetReq) (*oasUser, error)            { return nil, nil }
+func oasUpdate(ctx context.Context, r *oasUpdateReq) (*struct{}, error)     { return nil, nil }
+func oasList(ctx context.Context, r *oasListReq) (*oasPage[oasUser], error) { return nil, nil }
+
+// jsonAt 按路径取值，数字为数组下标
+func jsonAt(v any, path ...any) any {
+	for _, p := range path {
+		switch k := p.(type) {
+		case string:
+			m, _ := v.(map[string]any)
+			v = m[k]
+		case int:
+			s, _ := v.([]any)
+			if k >= len(s) {
+				return nil
+			}
+			v = s[k]
+		}
+	}
+	return v
+}
+
+// openAPIDoc 请求并解析 /openapi.json
+func openAPIDoc(t *testing.T, app *nomoyutest.App) map[string]any {
+	t.Helper()
+	rec := app.GET("/openapi.json")
+	nomoyutest.AssertStatus(t, rec, http.StatusOK)
+	var doc map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestOpenAPI(t *testing.T) {
+	app := nomoyutest.New(t, nomoyutest.WithConfig("auth:\n  enabled: true\n  mode: jwt\n  jwt:\n    secret: abc\n"),
+		nomoyutest.WithApp(func(a *nomoyu.App) {
+			a.WithOpenAPI().WithRoute(nomoyu.NewGroup("/api/v1").RequireAuth().Group("/users", func(g nomoyu.RouteGroup) nomoyu.RouteGroup {
+				return g.Typed(http.MethodGet, "", nomoyu.Handle(oasList), nomoyu.Summary("用户列表")).
+					Typed(http.MethodGet, "/:id", nomoyu.Handle(oasGet)).
+					Typed(http.MethodPut, "/:id", nomoyu.Handle(oasUpdate), nomoyu.Tags("admin")).
+					DELETE("/:id", func(c *gin.Context) {})
+			}))
+		}))
+
+	doc := openAPIDoc(t, app)
+	nomoyutest.AssertStatus(t, app.GET("/docs"), http.StatusOK)
+	at := func(path ...any) any { return jsonAt(doc, path...) }
+
+	if at("openapi") != "3.1.0" || !strings.Contains(at("info", "description").(string), "| 1000 |") {
+		t.Fatalf("info: %v", at("info"))
+	}
+	list := []any{"paths", "/api/v1/users", "get"}
+	if at(append(list, "summary")...) != "用户列表" || at(append(list, "parameters", 0, "schema", "minimum")...) != 1.0 {
+		t.Errorf("list operation: %v", at(list...))
+	}
+	if ref, _ := at(append(list, "responses", "200", "content", "application/json", "schema", "allOf", 1, "properties", "data", "$ref")...).(string); !strings.HasPrefix(ref, "#/components/schemas/oasPage_") {
+		t.Errorf("list response ref %q", ref)
+	}
+	if !reflect.DeepEqual(at(append(list, "security")...), []any{map[string]any{"bearerAuth": []any{}}}) {
+		t.Errorf("security: %v", at(append(list, "security")...))
+	}
+
+	get := []any{"paths", "/api/v1/users/{id}", "get", "parameters"}
+	for i, want := range []string{"id:path", "fields:query", "X-Tenant:header"} {
+		p := at(append(get, i)...).(map[string]any)
+		if p["name"].(string)+":"+p["in"].(string) != want {
+			t.Errorf("parameter %d: %v", i, p)
+		}
+	}
+
+	put := []any{"paths", "/api/v1/users/{id}", "put"}
+	body := append(put, "requestBody", "content", "application/json", "schema")
+	if at(append(body, "properties", "email", "format")...) != "email" || !reflect.DeepEqual(at(append(body, "required")...), []any{"name"}) {
+		t.Errorf("request body: %v", at(body...))
+	}
+	if !reflect.DeepEqual(at(append(put, "tags")...), []any{"admin"}) {
+		t.Errorf("tags: %v", at(append(put, "tags")...))
+	}
+	if at("paths", "/api/v1/users/{id}", "delete") == nil {
+		t.Error("plain gin handler not documented")
+	}
+
+	user := []any{"components", "schemas", "oasUser", "properties"}
+	if at(append(user, "boss", "$ref")...) != "#/components/schemas/oasUser" ||
+		at(append(user, "created_at", "format")...) != "date-time" ||
+		at(append(user, "name", "maxLength")...) != 32.0 ||
+		!reflect.DeepEqual(at(append(user, "role", "enum")...), []any{"admin", "user"}) {
+		t.Errorf("user schema: %v", at(user...))
+	}
+}
+
+type oasUserV2 struct {
+	ID       int64  `json:"id"`
+	FullName string `json:"full_name"`
+}
+
+func oasGetV2(ctx context.Context, r *oasGetReq) (*oasUserV2, error) { return nil, nil }
+
+func TestOpenAPIVersioning(t *testing.T) {
+	newApp := func(t *testing.T, strategy nomoyu.VersionStrategy, def string) *nomoyutest.App {
+		return nomoyutest.New(t, nomoyutest.WithApp(func(a *nomoyu.App) {
+			a.WithOpenAPI().WithVersioning(strategy, func(o *nomoyu.VersioningOption) { o.Default = def }).WithRoute(
+				nomoyu.NewGroup("/api").
+					Group("/users", func(g nomoyu.RouteGroup) nomoyu.RouteGroup {
+						return g.Version("v1").Typed(http.MethodGet, "/:id", nomoyu.Handle(oasGet))
+					}).
+					Group("/users", func(g nomoyu.RouteGroup) nomoyu.RouteGroup {
+						return g.Version("v2").Typed(http.MethodGet, "/:id", nomoyu.Handle(oasGetV2))
+					}))
+		}))
+	}
+	dataRef := func(content any) any {
+		return jsonAt(content, "schema", "allOf", 1, "properties", "data", "$ref")
+	}
+
+	t.Run("path", func(t *testing.T) {
+		doc := openAPIDoc(t, newApp(t, nomoyu.VersionByPath, ""))
+		if jsonAt(doc, "paths", "/api/v1/users/{id}", "get") == nil || jsonAt(doc, "paths", "/api/v2/users/{id}", "get") == nil {
+			t.Fatalf("paths: %v", jsonAt(doc, "paths"))
+		}
+	})
+
+	t.Run("header", func(t *testing.T) {
+		doc := openAPIDoc(t, newApp(t, nomoyu.VersionByHeader, "v1"))
+		paths := jsonAt(doc, "paths").(map[string]any)
+		if len(paths) != 1 || paths["/api/users/{id}"] == nil {
+			t.Fatalf("paths: %v", paths)
+		}
+		get := []any{"paths", "/api/users/{id}", "get"}
+		var version map[string]any
+		params, _ := jsonAt(doc, append(get, "parameters")...).([]any)
+		for _, p := range params {
+			if p := p.(map[string]any); p["name"] == "X-API-Version" && p["in"] == "header" {
+				version = p
+			}
+		}
+		if version == nil {
+			t.Fatalf("no version header parameter: %v", params)
+		}
+		if !reflect.DeepEqual(jsonAt(version, "schema", "enum"), []any{"v1", "v2"}) || jsonAt(version, "schema", "default") != "v1" {
+			t.Errorf("version parameter: %v", version)
+		}
+		// 未声明版本时命中默认版本 v1，文档按 v1 描述
+		if ref := dataRef(jsonAt(doc, append(get, "responses", "200", "content", "application/json")...)); ref != "#/components/schemas/oasUser" {
+			t.Errorf("response ref %v", ref)
+		}
+	})
+
+	t.Run("accept", func(t *testing.T) {
+		doc := openAPIDoc(t, newApp(t, nomoyu.VersionByAccept, ""))
+		content := []any{"paths", "/api/users/{id}", "get", "responses", "200", "content"}
+		for mt, want := range map[string]string{
+			"application/json":            "#/components/schemas/oasUserV2", // 未声明版本时取最新版本
+			"application/json; version=1": "#/components/schemas/oasUser",
+			"application/json; version=2": "#/components/schemas/oasUserV2",
+		} {
+			if ref := dataRef(jsonAt(doc, append(content, mt)...)); ref != want {
+				t.Errorf("%s: %v, want %s", mt, ref, want)
+			}
+		}
+		if jsonAt(doc, "paths", "/api/v1/users/{id}") != nil {
+			t.Errorf("versioned path documented: %v", jsonAt(doc, "paths"))
+		}
+	})
+}
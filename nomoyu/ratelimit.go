@@ -0,0 +1,47 @@
+package nomoyu
+
+import (
+	"github.com/nomoyu/go-gin-framework/internal/middleware"
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+	"github.com/nomoyu/go-gin-framework/pkg/logger"
+)
+
+// RateLimitOption 按客户端地址的令牌桶限流
+type RateLimitOption struct {
+	Rate     float64 // 每个客户端每秒请求数
+	Burst    int     // 允许的突发请求数，默认等于 Rate
+	FromUser bool
+}
+
+// WithRateLimit 开启限流（优先于配置 rate_limit.*，不随配置热更新）
+func (a *App) WithRateLimit(opt RateLimitOption) *App {
+	opt.FromUser = true
+	a.rateLimitOption = &opt
+	return a
+}
+
+// initRateLimit 限流中间件始终挂载：未启用时直接放行，配置 rate_limit.* 变化时调整限额
+func initRateLimit(app *App) {
+	if opt := app.rateLimitOption; opt != nil && opt.FromUser {
+		burst := opt.Burst
+		if burst <= 0 {
+			burst = max(int(opt.Rate), 1)
+		}
+		app.engine.Use(middleware.NewRateLimiter(true, opt.Rate, burst).Handler())
+		logger.Infof("rate limit enabled: rate=%v/s, burst=%d", opt.Rate, burst)
+		return
+	}
+
+	conf := config.Current().RateLimit
+	limiter := middleware.NewRateLimiter(conf.Enabled, conf.Rate, conf.Burst)
+	if conf.Enabled {
+		logger.Infof("rate limit enabled: rate=%v/s, burst=%d", conf.Rate, conf.Burst)
+	}
+	app.subscribeConfig(func() func() {
+		return config.OnChange("rate_limit", func(_, conf config.RateLimit) {
+			limiter.SetLimit(conf.Enabled, conf.Rate, conf.Burst)
+			logger.Infof("config reload: rate limit updated (enabled=%v, rate=%v/s, burst=%d)", conf.Enabled, conf.Rate, conf.Burst)
+		})
+	})
+	app.engine.Use(limiter.Handler())
+}
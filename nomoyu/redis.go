@@ -2,6 +2,8 @@ package nomoyu
 
 import (
 	"context"
+
+	"github.com/gin-gonic/gin"
 	"github.com/nomoyu/go-gin-framework/pkg/config"
 	"github.com/nomoyu/go-gin-framework/pkg/logger"
 	"github.com/nomoyu/go-gin-framework/pkg/redisx"
@@ -23,50 +25,52 @@ func (a *App) WithRedisAddr(addr, password string, db int) *App {
 	return a.WithRedis(redisx.Config{Addr: addr, Password: password, DB: db})
 }
 
-// Start() 里调用的一键初始化（配置优先 & 用户可覆盖）
-func initRedisFromConfigIfPresent(app *App) {
-	// 若用户已 WithRedis -> 初始化并返回
+// redisModule 内置 Redis 模块（模块名 "redis"）
+type redisModule struct {
+	cfg redisx.Config
+}
+
+func (m *redisModule) Name() string           { return "redis" }
+func (m *redisModule) Register(r *gin.Engine) {}
+
+func (m *redisModule) Init(ctx context.Context, app *App) error {
 	logger.Info("start init nomoyu redis...")
+	if err := redisx.Init(m.cfg); err != nil {
+		return err
+	}
+	logger.Infof("init nomoyu redis success（%s）", modeLabel(m.cfg))
+	return nil
+}
+
+func (m *redisModule) Stop(ctx context.Context) error {
+	logger.Info("close Redis connect...")
+	return redisx.Close()
+}
+
+// 根据 WithRedis 或配置文件生成 Redis 模块（代码配置优先），未配置时返回 nil
+func newRedisModuleIfPresent(app *App) Module {
 	if app.redisOption != nil && app.redisOption.FromUser {
-		logger.Info("start init redis with code")
-		if err := redisx.Init(app.redisOption.C); err != nil {
-			logger.Errorf("init nomoyu redis failed(WithRedis): %v", err)
-		} else {
-			app.OnShutdown(func(ctx context.Context) error {
-				logger.Info("close Redis connect...")
-				return redisx.Close()
-			})
-		}
-		return
+		return &redisModule{cfg: app.redisOption.C}
 	}
-	logger.Info("start init redis with config")
-	// 没有 WithRedis，则尝试从配置读取
-	rc := config.Conf.Redis
+
+	rc := config.Current().Redis
 	// 既支持单点也支持集群；只要给了 addr 或 addrs 就尝试初始化
-	if rc.Addr != "" || len(rc.Addrs) > 0 {
-		logger.Info("start init redis with config" + rc.Addr)
-		cfg := redisx.Config{
-			Mode:         rc.Mode,
-			Addr:         rc.Addr,
-			Addrs:        rc.Addrs,
-			Password:     rc.Password,
-			DB:           rc.DB,
-			PoolSize:     rc.PoolSize,
-			DialTimeout:  rc.DialTimeout,
-			ReadTimeout:  rc.ReadTimeout,
-			WriteTimeout: rc.WriteTimeout,
-		}
-		if err := redisx.Init(cfg); err != nil {
-			logger.Errorf("init nomoyu redis failed: %v", err)
-			return
-		}
-		app.redisOption = &RedisOption{C: cfg, FromUser: false}
-		logger.Infof("init nomoyu redis success（%s）", modeLabel(cfg))
-		app.OnShutdown(func(ctx context.Context) error {
-			logger.Info("close Redis connect...")
-			return redisx.Close()
-		})
+	if rc.Addr == "" && len(rc.Addrs) == 0 {
+		return nil
+	}
+	cfg := redisx.Config{
+		Mode:         rc.Mode,
+		Addr:         rc.Addr,
+		Addrs:        rc.Addrs,
+		Password:     rc.Password,
+		DB:           rc.DB,
+		PoolSize:     rc.PoolSize,
+		DialTimeout:  rc.DialTimeout,
+		ReadTimeout:  rc.ReadTimeout,
+		WriteTimeout: rc.WriteTimeout,
 	}
+	app.redisOption = &RedisOption{C: cfg, FromUser: false}
+	return &redisModule{cfg: cfg}
 }
 
 func modeLabel(c redisx.Config) string {
@@ -0,0 +1,169 @@
+package nomoyu_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/nomoyu"
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+	"github.com/nomoyu/go-gin-framework/pkg/logger"
+	"github.com/nomoyu/go-gin-framework/pkg/scheduler"
+)
+
+const reloadConfig = `
+app: {name: reload, env: test}
+log: {level: %s, path: %q}
+config: {watch: true}
+cors: {enabled: true, allow_origins: [%q]}
+rate_limit: {enabled: %v, rate: 1, burst: 2}
+scheduler: {disabled: [%s]}
+payment: {merchant: %s}
+`
+
+type reloadPayment struct {
+	Merchant string `mapstructure:"merchant"`
+}
+
+func TestConfigWatchAppliesLive(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("GO_ENV", "reload")
+	prev := config.Current()
+	t.Cleanup(func() { config.Set(prev) })
+
+	file := filepath.Join(dir, "config.reload.yaml")
+	write := func(text string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(fmt.Sprintf(reloadConfig, "info", dir, "http://a.com", false, "tick", "m1"))
+	if err := config.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	var ticks atomic.Int32
+	sched := scheduler.New()
+	app := nomoyu.New().
+		WithScheduler(sched).
+		WithCronTasks(scheduler.Task{Name: "tick", Spec: "@every 10ms", Job: func(context.Context) error {
+			ticks.Add(1)
+			return nil
+		}}).
+		WithRoute(nomoyu.NewGroup("/x").GET("", func(c *gin.Context) { c.String(200, "ok") }))
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+	defer app.Shutdown()
+	sched.Start()
+	defer sched.Stop(context.Background())
+
+	payments := make(chan reloadPayment, 4)
+	cancel := config.OnChange("payment", func(_, p reloadPayment) { payments <- p })
+	defer cancel()
+
+	get := func(origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/x", nil)
+		req.Header.Set("Origin", origin)
+		rec := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+	allowed := func(origin string) bool {
+		return get(origin).Header().Get("Access-Control-Allow-Origin") == origin
+	}
+
+	if !allowed("http://a.com") || allowed("http://b.com") {
+		t.Fatal("initial cors not applied")
+	}
+	for i := 0; i < 5; i++ {
+		if rec := get("http://a.com"); rec.Code != http.StatusOK {
+			t.Fatalf("rate limit disabled, got %d", rec.Code)
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := ticks.Load(); n != 0 {
+		t.Fatalf("disabled task ran %d times", n)
+	}
+
+	write(fmt.Sprintf(reloadConfig, "debug", dir, "http://b.com", true, "", "m2"))
+	select {
+	case p := <-payments:
+		if p.Merchant != "m2" {
+			t.Fatalf("payment = %+v", p)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("config not reloaded")
+	}
+
+	if logger.GetLevel() != "debug" {
+		t.Fatalf("log level = %s", logger.GetLevel())
+	}
+	if allowed("http://a.com") || !allowed("http://b.com") {
+		t.Fatal("cors not reloaded")
+	}
+	codes := []int{get("http://b.com").Code, get("http://b.com").Code, get("http://b.com").Code}
+	if codes[2] != http.StatusTooManyRequests {
+		t.Fatalf("rate limit not reloaded: %v", codes)
+	}
+	deadline := time.Now().Add(time.Second)
+	for ticks.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if ticks.Load() == 0 {
+		t.Fatal("task not resumed")
+	}
+
+	// 无法解析的文件被拒绝，保留当前配置
+	write("log: [broken")
+	time.Sleep(300 * time.Millisecond)
+	if config.Current().Log.Level != "debug" || config.Current().RateLimit.Rate != 1 {
+		t.Fatalf("broken file replaced config: %+v", config.Current().Log)
+	}
+}
+
+func TestBuildFailureStopsConfigWatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	t.Chdir(dir)
+	prev := config.Current()
+	t.Cleanup(func() { config.Set(prev) })
+
+	write := func(level string) {
+		t.Helper()
+		text := fmt.Sprintf("app: {name: reload, env: test}\nlog: {level: %s, path: %q}\nconfig: {watch: true}\n", level, dir)
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("info")
+	if err := config.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []string
+	err := nomoyu.New().
+		WithModule(&lifecycleModule{name: "a", initErr: errors.New("boom"), events: &events}).
+		Build()
+	if err == nil {
+		t.Fatal("expected init error")
+	}
+
+	// Build 失败后不再监听配置文件
+	write("warn")
+	time.Sleep(500 * time.Millisecond)
+	if level := config.Current().Log.Level; level != "info" {
+		t.Fatalf("config reloaded after failed Build: log level %s", level)
+	}
+}
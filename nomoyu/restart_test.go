@@ -0,0 +1,218 @@
+//go:build !windows
+
+package nomoyu_test
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/nomoyu"
+)
+
+// helperEnv 为 1 时 TestHelperProcess 在子进程中运行应用
+const helperEnv = "NOMOYU_TEST_HELPER"
+
+// TestHelperProcess 不是真正的测试：由 startHelper 在子进程中运行，Run(NOMOYU_TEST_ADDR) 并开启平滑重启，
+// GET /proc/pid 返回进程号；NOMOYU_TEST_LISTEN_PID=self 时模拟 systemd 设置 LISTEN_PID
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv(helperEnv) != "1" {
+		return
+	}
+	loadTestConfig(t)
+	if os.Getenv("NOMOYU_TEST_LISTEN_PID") == "self" {
+		os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	}
+	app := nomoyu.New().WithGracefulRestart().WithRoute(nomoyu.NewGroup("/proc").GET("/pid", func(c *gin.Context) {
+		c.String(http.StatusOK, strconv.Itoa(os.Getpid()))
+	}))
+	if admin := os.Getenv("NOMOYU_TEST_ADMIN"); admin != "" {
+		app.WithAdminServer(admin)
+	}
+	if err := app.Run(os.Getenv("NOMOYU_TEST_ADDR")); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// helper 运行 TestHelperProcess 的子进程
+type helper struct {
+	cmd    *exec.Cmd
+	stderr string
+	exited chan error
+}
+
+// startHelper 以 env 与 ExtraFiles（子进程中 fd 从 3 开始）启动子进程，测试结束时仍在运行则杀掉
+func startHelper(t *testing.T, env []string, files ...*os.File) *helper {
+	t.Helper()
+	stderr, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stderr.Close()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+	cmd.Env = append(append(os.Environ(), helperEnv+"=1"), env...)
+	cmd.Stderr = stderr
+	cmd.ExtraFiles = files
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	h := &helper{cmd: cmd, stderr: stderr.Name(), exited: make(chan error, 1)}
+	go func() { h.exited <- cmd.Wait() }()
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		<-h.exited
+	})
+	return h
+}
+
+func (h *helper) output() string {
+	out, _ := os.ReadFile(h.stderr)
+	return string(out)
+}
+
+// wait 等待子进程退出
+func (h *helper) wait(t *testing.T, timeout time.Duration) error {
+	t.Helper()
+	select {
+	case err := <-h.exited:
+		h.exited <- err // 留给 Cleanup
+		return err
+	case <-time.After(timeout):
+		t.Fatalf("helper process still running after %s\n%s", timeout, h.output())
+		return nil
+	}
+}
+
+// pidAt 请求 addr 上的 /proc/pid
+func pidAt(addr string) (int, error) {
+	client := &http.Client{Timeout: time.Second, Transport: &http.Transport{DisableKeepAlives: true}}
+	resp, err := client.Get("http://" + addr + "/proc/pid")
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(string(body))
+}
+
+// waitPid 轮询 addr 直到由 match 接受的进程提供服务，返回该进程号
+func waitPid(t *testing.T, h *helper, addr string, match func(pid int) bool) int {
+	t.Helper()
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if pid, err := pidAt(addr); err == nil && match(pid) {
+			return pid
+		}
+	}
+	t.Fatalf("%s not served by the expected process\n%s", addr, h.output())
+	return 0
+}
+
+// tcpListenerFile 在本机随机端口监听，返回地址与可传给子进程的 fd
+func tcpListenerFile(t *testing.T) (string, *os.File) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	f, err := ln.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return ln.Addr().String(), f
+}
+
+func TestInheritedListeners(t *testing.T) {
+	mainAddr, mainFile := tcpListenerFile(t)
+	adminAddr, adminFile := tcpListenerFile(t)
+	ownAddr := freeAddr(t)
+	h := startHelper(t, []string{
+		"NOMOYU_LISTEN_FDS=admin:4,main:3",
+		"NOMOYU_TEST_ADDR=" + ownAddr,
+		"NOMOYU_TEST_ADMIN=" + freeAddr(t),
+	}, mainFile, adminFile)
+
+	// 按名称使用父进程交接的 socket，而不是自行监听地址
+	waitPid(t, h, mainAddr, func(pid int) bool { return pid == h.cmd.Process.Pid })
+	resp, err := http.Get("http://" + adminAddr + "/log/level")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("admin via inherited listener: %d", resp.StatusCode)
+	}
+	if _, err := pidAt(ownAddr); err == nil {
+		t.Fatalf("helper also listens on %s", ownAddr)
+	}
+}
+
+func TestInheritedListenersInvalidSpec(t *testing.T) {
+	h := startHelper(t, []string{"NOMOYU_LISTEN_FDS=main:x", "NOMOYU_TEST_ADDR=" + freeAddr(t)})
+	if err := h.wait(t, 10*time.Second); err == nil {
+		t.Fatal("helper started with an invalid NOMOYU_LISTEN_FDS")
+	}
+	if out := h.output(); !strings.Contains(out, "invalid NOMOYU_LISTEN_FDS") {
+		t.Fatalf("stderr: %s", out)
+	}
+}
+
+// TestGracefulRestart 发送 SIGHUP：新进程接管同一个 socket，旧进程退出，期间请求不中断
+func TestGracefulRestart(t *testing.T) {
+	if testing.Short() {
+		t.Skip("starts and restarts a child process")
+	}
+	addr := freeAddr(t)
+	h := startHelper(t, []string{"NOMOYU_TEST_ADDR=" + addr})
+	oldPid := waitPid(t, h, addr, func(pid int) bool { return pid == h.cmd.Process.Pid })
+
+	if err := h.cmd.Process.Signal(syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	var newPid int
+	var failed []error
+	for deadline := time.Now().Add(10 * time.Second); newPid == 0 && time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		pid, err := pidAt(addr)
+		switch {
+		case err != nil:
+			failed = append(failed, err)
+		case pid != oldPid:
+			newPid = pid
+		}
+	}
+	if newPid == 0 {
+		t.Fatalf("no new process after SIGHUP\n%s", h.output())
+	}
+	t.Cleanup(func() { _ = syscall.Kill(newPid, syscall.SIGKILL) })
+	if len(failed) > 0 {
+		t.Errorf("%d requests failed during restart: %v", len(failed), failed)
+	}
+
+	// 旧进程优雅退出，新进程继续服务
+	if err := h.wait(t, 10*time.Second); err != nil {
+		t.Fatalf("old process: %v\n%s", err, h.output())
+	}
+	if pid, err := pidAt(addr); err != nil || pid != newPid {
+		t.Fatalf("after restart: pid %d, %v", pid, err)
+	}
+	if err := syscall.Kill(newPid, syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+}
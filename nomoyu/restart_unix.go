@@ -0,0 +1,216 @@
+//go:build !windows
+
+package nomoyu
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/nomoyu/go-gin-framework/pkg/logger"
+)
+
+const (
+	// 父进程传给子进程的监听 socket，形如 "main:4,admin:5"
+	envListenFDs = "NOMOYU_LISTEN_FDS"
+	// 子进程就绪后向该 fd 写入一个字节通知父进程
+	envReadyFD = "NOMOYU_READY_FD"
+
+	handoffReadyTimeout = 30 * time.Second
+)
+
+func restartSignals() []os.Signal {
+	return []os.Signal{syscall.SIGHUP, syscall.SIGUSR2}
+}
+
+// inheritedListener 查找父进程交接过来的同名 socket
+func inheritedListener(name string) (net.Listener, bool, error) {
+	spec := os.Getenv(envListenFDs)
+	if spec == "" {
+		return nil, false, nil
+	}
+	for _, item := range strings.Split(spec, ",") {
+		n, fdStr, ok := strings.Cut(item, ":")
+		if !ok || n != name {
+			continue
+		}
+		fd, err := strconv.Atoi(fdStr)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid %s: %s", envListenFDs, spec)
+		}
+		f := os.NewFile(uintptr(fd), name)
+		defer f.Close()
+		ln, err := net.FileListener(f)
+		if err != nil {
+			return nil, false, fmt.Errorf("inherit listener %s: %w", name, err)
+		}
+		// 与自行创建的 unix socket 保持一致：最终退出时删除 socket 文件
+		if ul, ok := ln.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(true)
+		}
+		logger.Infof("inherited %s listener from parent process (fd=%d)", name, fd)
+		return ln, true, nil
+	}
+	return nil, false, nil
+}
+
+// handoff 启动新进程并交接所有监听 socket，等待其就绪；失败时当前进程继续服务
+func (a *App) handoff() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer readyR.Close()
+
+	// files[i] 在子进程中的 fd 为 3+i
+	files := []*os.File{readyW}
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+	specs := make([]string, 0, len(a.listeners))
+	for _, l := range a.listeners {
+		fl, ok := l.ln.(interface{ File() (*os.File, error) })
+		if !ok {
+			return fmt.Errorf("listener %s (%T) can not be handed off", l.name, l.ln)
+		}
+		f, err := fl.File()
+		if err != nil {
+			return fmt.Errorf("dup listener %s: %w", l.name, err)
+		}
+		files = append(files, f)
+		specs = append(specs, fmt.Sprintf("%s:%d", l.name, 3+len(files)-1))
+	}
+
+	// 不用 exec.Cmd：它通过 os.File.Fd() 把 fd 切换为阻塞模式，而 dup 出的 fd 与当前进程的 listener 共享文件状态，
+	// 会让当前进程的 Accept 阻塞在系统调用中，停机时 listener 无法关闭
+	fds := []uintptr{os.Stdin.Fd(), os.Stdout.Fd(), os.Stderr.Fd()}
+	for _, f := range files {
+		fd, err := rawFd(f)
+		if err != nil {
+			return err
+		}
+		fds = append(fds, fd)
+	}
+	env := append(withoutEnv(os.Environ(), envListenFDs, envReadyFD),
+		envListenFDs+"="+strings.Join(specs, ","),
+		envReadyFD+"=3",
+	)
+	pid, _, err := syscall.StartProcess(exe, append([]string{exe}, os.Args[1:]...), &syscall.ProcAttr{Env: env, Files: fds})
+	if err != nil {
+		return fmt.Errorf("start new process: %w", err)
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	go func() { _, _ = proc.Wait() }()
+	logger.Infof("new process started (pid=%d), waiting for it to be ready...", pid)
+
+	// 父进程必须关闭自己持有的写端，子进程异常退出时读端才能收到 EOF
+	_ = readyW.Close()
+	files = files[1:]
+
+	ready := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		if _, err := readyR.Read(buf); err != nil {
+			ready <- errors.New("new process exited before ready")
+			return
+		}
+		ready <- nil
+	}()
+
+	select {
+	case err := <-ready:
+		if err != nil {
+			return err
+		}
+		// socket 文件已由新进程使用，旧进程关闭 listener 时不能删除
+		for _, l := range a.listeners {
+			if ul, ok := l.ln.(*net.UnixListener); ok {
+				ul.SetUnlinkOnClose(false)
+			}
+		}
+		return nil
+	case <-time.After(handoffReadyTimeout):
+		_ = proc.Kill()
+		return fmt.Errorf("new process not ready in %s", handoffReadyTimeout)
+	}
+}
+
+// rawFd 取 f 的 fd，不改变其阻塞模式（f 需在使用期间保持打开）
+func rawFd(f *os.File) (uintptr, error) {
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+	var fd uintptr
+	if err := rc.Control(func(s uintptr) { fd = s }); err != nil {
+		return 0, err
+	}
+	return fd, nil
+}
+
+// notifyReady 所有监听就绪后调用：通知父进程（平滑重启）与 systemd（Type=notify）
+func (a *App) notifyReady() {
+	if fdStr := os.Getenv(envReadyFD); fdStr != "" {
+		if fd, err := strconv.Atoi(fdStr); err == nil {
+			f := os.NewFile(uintptr(fd), "ready")
+			_, _ = f.Write([]byte{1})
+			_ = f.Close()
+		}
+		// 避免再次重启时把过期的 fd 传给下一代进程
+		_ = os.Unsetenv(envReadyFD)
+		_ = os.Unsetenv(envListenFDs)
+	}
+
+	// MAINPID 让 systemd 在父进程退出后把当前进程视为主进程（需 NotifyAccess=all）
+	if err := sdNotify(fmt.Sprintf("READY=1\nMAINPID=%d", os.Getpid())); err != nil {
+		logger.Warnf("sd_notify failed: %v", err)
+	}
+}
+
+// sdNotify 向 systemd 发送状态（未设置 NOTIFY_SOCKET 时忽略）
+func sdNotify(state string) error {
+	sock := os.Getenv("NOTIFY_SOCKET")
+	if sock == "" {
+		return nil
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: sock, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+func withoutEnv(env []string, keys ...string) []string {
+	out := make([]string, 0, len(env))
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		skip := false
+		for _, k := range keys {
+			if name == k {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			out = append(out, kv)
+		}
+	}
+	return out
+}
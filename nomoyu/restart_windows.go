@@ -0,0 +1,23 @@
+//go:build windows
+
+package nomoyu
+
+import (
+	"errors"
+	"net"
+	"os"
+)
+
+func restartSignals() []os.Signal {
+	return nil
+}
+
+func inheritedListener(name string) (net.Listener, bool, error) {
+	return nil, false, nil
+}
+
+func (a *App) handoff() error {
+	return errors.New("graceful restart is not supported on windows")
+}
+
+func (a *App) notifyReady() {}
@@ -1,23 +1,105 @@
 package nomoyu
 
 import (
+	"net/http"
+	"path"
+	"strings"
+
 	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/internal/middleware"
+	"github.com/nomoyu/go-gin-framework/pkg/sse"
+	"github.com/nomoyu/go-gin-framework/pkg/ws"
 )
 
+// RouteGroup 路由分组（链式声明）；子分组继承前缀、中间件与 RequireAuth
+//
+// RouteGroup 是值类型，每次调用返回新的分组而不修改原分组，可以从同一个分组派生出不同的声明：
+//
+//	base := nomoyu.NewGroup("/api").Use(mw)
+//	a := base.GET("/a", ha) // 只有 /a
+//	b := base.GET("/b", hb) // 只有 /b
 type RouteGroup struct {
 	prefix      string
-	routes      []func(rg *gin.RouterGroup)
 	middleware  []gin.HandlerFunc
 	requireAuth bool
+	routes      []routeSpec
+	children    []RouteGroup
+	version     string
+	deprecation *Deprecation
+}
+
+type routeSpec struct {
+	methods    []string
+	path       string
+	handler    gin.HandlerFunc
+	types      *HandlerTypes // 由 Handle 创建的 handler 的请求/响应类型
+	middleware []gin.HandlerFunc
+	auth       *bool // nil 表示继承分组
+	ws         ws.Handler
+	sse        sse.Handler
+
+	summary     string
+	description string
+	tags        []string
+}
+
+// RouteOption 单个路由的选项（中间件、认证覆盖等）
+type RouteOption func(r *routeSpec)
+
+// WithMiddleware 只作用于当前路由的中间件，在分组中间件之后执行
+func WithMiddleware(m ...gin.HandlerFunc) RouteOption {
+	return func(r *routeSpec) {
+		r.middleware = append(r.middleware, m...)
+	}
+}
+
+// AuthRequired 当前路由需要认证（即使分组未声明 RequireAuth）
+func AuthRequired() RouteOption {
+	return func(r *routeSpec) {
+		required := true
+		r.auth = &required
+	}
+}
+
+// Public 当前路由跳过分组的认证（如登录、回调接口）
+func Public() RouteOption {
+	return func(r *routeSpec) {
+		required := false
+		r.auth = &required
+	}
+}
+
+// Summary 接口摘要（OpenAPI 文档）
+func Summary(summary string) RouteOption {
+	return func(r *routeSpec) {
+		r.summary = summary
+	}
+}
+
+// Description 接口说明（OpenAPI 文档）
+func Description(desc string) RouteOption {
+	return func(r *routeSpec) {
+		r.description = desc
+	}
+}
+
+// Tags 接口分类（OpenAPI 文档），默认取所在分组路径的最后一段
+func Tags(tags ...string) RouteOption {
+	return func(r *routeSpec) {
+		r.tags = append(r.tags, tags...)
+	}
+}
+
+// anyMethods 与 gin RouterGroup.Any 一致
+var anyMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodHead, http.MethodOptions, http.MethodDelete, http.MethodConnect,
+	http.MethodTrace,
 }
 
 // NewGroup 创建分组路由
 func NewGroup(prefix string) RouteGroup {
-	return RouteGroup{
-		prefix:     prefix,
-		routes:     []func(rg *gin.RouterGroup){},
-		middleware: []gin.HandlerFunc{},
-	}
+	return RouteGroup{prefix: prefix}
 }
 
 // RequireAuth 开启认证（内部使用时统一注册中间件）
@@ -26,38 +108,179 @@ func (rg RouteGroup) RequireAuth() RouteGroup {
 	return rg
 }
 
-// GET 注册 GET 路由
-func (rg RouteGroup) GET(path string, handler gin.HandlerFunc) RouteGroup {
-	rg.routes = append(rg.routes, func(group *gin.RouterGroup) {
-		group.GET(path, handler)
-	})
+// Use 添加中间件
+func (rg RouteGroup) Use(m ...gin.HandlerFunc) RouteGroup {
+	rg.middleware = append(rg.middleware[:len(rg.middleware):len(rg.middleware)], m...)
 	return rg
 }
 
-// POST 注册 POST 路由
-func (rg RouteGroup) POST(path string, handler gin.HandlerFunc) RouteGroup {
-	rg.routes = append(rg.routes, func(group *gin.RouterGroup) {
-		group.POST(path, handler)
-	})
+// Group 声明子分组，fn 返回声明好的子分组，如
+// rg.Group("/users", func(g nomoyu.RouteGroup) nomoyu.RouteGroup { return g.GET("/:id", get) })
+func (rg RouteGroup) Group(prefix string, fn func(g RouteGroup) RouteGroup) RouteGroup {
+	child := NewGroup(prefix)
+	if fn != nil {
+		child = fn(child)
+	}
+	rg.children = append(rg.children[:len(rg.children):len(rg.children)], child)
 	return rg
 }
 
-func (rg RouteGroup) PUT(path string, handler gin.HandlerFunc) RouteGroup {
-	rg.routes = append(rg.routes, func(group *gin.RouterGroup) {
-		group.PUT(path, handler)
-	})
-	return rg
+// Handle 注册任意方法的路由
+func (rg RouteGroup) Handle(method, path string, handler gin.HandlerFunc, opts ...RouteOption) RouteGroup {
+	return rg.Match([]string{method}, path, handler, opts...)
 }
 
-func (rg RouteGroup) DELETE(path string, handler gin.HandlerFunc) RouteGroup {
-	rg.routes = append(rg.routes, func(group *gin.RouterGroup) {
-		group.DELETE(path, handler)
-	})
-	return rg
+// Match 为多个方法注册同一个 handler
+func (rg RouteGroup) Match(methods []string, path string, handler gin.HandlerFunc, opts ...RouteOption) RouteGroup {
+	return rg.addRoute(routeSpec{methods: methods, path: path, handler: handler}, opts)
 }
 
-// Use 添加中间件
-func (rg RouteGroup) Use(m ...gin.HandlerFunc) RouteGroup {
-	rg.middleware = append(rg.middleware, m...)
+// Typed 注册 nomoyu.Handle 创建的类型化 handler，并记录请求/响应类型（OpenAPI、路由表使用），如
+// rg.Typed(http.MethodGet, "/:id", nomoyu.Handle(getUser))
+func (rg RouteGroup) Typed(method, path string, handler TypedHandler, opts ...RouteOption) RouteGroup {
+	types := handler.Types()
+	return rg.addRoute(routeSpec{methods: []string{method}, path: path, handler: handler.Gin(), types: &types}, opts)
+}
+
+// addRoute 应用路由选项后追加到分组；总是复制切片，不影响派生出当前分组的其他分组
+func (rg RouteGroup) addRoute(r routeSpec, opts []RouteOption) RouteGroup {
+	for _, opt := range opts {
+		opt(&r)
+	}
+	rg.routes = append(rg.routes[:len(rg.routes):len(rg.routes)], r)
 	return rg
 }
+
+// Any 注册全部 HTTP 方法
+func (rg RouteGroup) Any(path string, handler gin.HandlerFunc, opts ...RouteOption) RouteGroup {
+	return rg.Match(anyMethods, path, handler, opts...)
+}
+
+// GET 注册 GET 路由
+func (rg RouteGroup) GET(path string, handler gin.HandlerFunc, opts ...RouteOption) RouteGroup {
+	return rg.Handle(http.MethodGet, path, handler, opts...)
+}
+
+// POST 注册 POST 路由
+func (rg RouteGroup) POST(path string, handler gin.HandlerFunc, opts ...RouteOption) RouteGroup {
+	return rg.Handle(http.MethodPost, path, handler, opts...)
+}
+
+func (rg RouteGroup) PUT(path string, handler gin.HandlerFunc, opts ...RouteOption) RouteGroup {
+	return rg.Handle(http.MethodPut, path, handler, opts...)
+}
+
+func (rg RouteGroup) DELETE(path string, handler gin.HandlerFunc, opts ...RouteOption) RouteGroup {
+	return rg.Handle(http.MethodDelete, path, handler, opts...)
+}
+
+func (rg RouteGroup) PATCH(path string, handler gin.HandlerFunc, opts ...RouteOption) RouteGroup {
+	return rg.Handle(http.MethodPatch, path, handler, opts...)
+}
+
+func (rg RouteGroup) HEAD(path string, handler gin.HandlerFunc, opts ...RouteOption) RouteGroup {
+	return rg.Handle(http.MethodHead, path, handler, opts...)
+}
+
+func (rg RouteGroup) OPTIONS(path string, handler gin.HandlerFunc, opts ...RouteOption) RouteGroup {
+	return rg.Handle(http.MethodOptions, path, handler, opts...)
+}
+
+// groupScope 注册时从外层分组累积下来的状态
+type groupScope struct {
+	prefix      string // 实际注册的前缀（含版本段）
+	public      string // 不含版本段的前缀，按请求头/Accept 声明版本时使用
+	middleware  []gin.HandlerFunc
+	requireAuth bool
+	version     string
+	versionAt   int
+	deprecation *Deprecation
+}
+
+// registerGroup 把分组（含子分组）挂载到 engine；
+// 每个路由的处理链为：版本 -> 认证 -> 外层到内层的分组中间件 -> 路由中间件 -> handler
+func (a *App) registerGroup(group RouteGroup) {
+	a.registerScope(group, groupScope{prefix: "/", public: "/"})
+}
+
+func (a *App) registerScope(group RouteGroup, parent groupScope) {
+	scope := parent
+	scope.middleware = append(append([]gin.HandlerFunc{}, parent.middleware...), group.middleware...)
+	scope.requireAuth = parent.requireAuth || group.requireAuth
+	if group.version != "" {
+		scope.version = group.version
+		scope.versionAt = pathSegments(parent.public)
+		scope.prefix = joinPaths(parent.prefix, group.version)
+	}
+	if group.deprecation != nil {
+		scope.deprecation = group.deprecation
+	}
+	scope.prefix = joinPaths(scope.prefix, group.prefix)
+	scope.public = joinPaths(parent.public, group.prefix)
+
+	for _, r := range group.routes {
+		fullPath := joinPaths(scope.prefix, r.path)
+		requireAuth := scope.requireAuth
+		if r.auth != nil {
+			requireAuth = *r.auth
+		}
+
+		var chain []gin.HandlerFunc
+		meta := routeMeta{group: scope.prefix, summary: r.summary, description: r.description, tags: r.tags}
+		if scope.version != "" {
+			chain = append(chain, versionMiddleware(scope.version, scope.deprecation))
+			meta.version = scope.version
+			if a.versionRouter != nil {
+				meta.publicPath = joinPaths(scope.public, r.path)
+			}
+		}
+		meta.deprecated = scope.deprecation != nil
+		handler := r.handler
+		if r.ws != nil {
+			// 浏览器无法设置请求头：认证前把 query/子协议中的 token 转成 Authorization
+			handler = a.wsHandler(r.ws)
+			chain = append(chain, wsTokenMiddleware)
+			meta.handler = funcName(r.ws)
+			meta.protocol = protocolWebSocket
+		} else if r.sse != nil {
+			handler = a.sseHandler(r.sse)
+			meta.handler = funcName(r.sse)
+			meta.protocol = protocolSSE
+		} else if r.types != nil {
+			meta.types = r.types
+		}
+		// ✅ 如果启用了权限认证模块并且该路由需要认证
+		if requireAuth && a.authOption != nil && !a.globalAuth {
+			chain = append(chain, middleware.AuthMiddleware(a.authOption.Strategy))
+			meta.requireAuth = true
+		}
+		mws := append(append([]gin.HandlerFunc{}, scope.middleware...), r.middleware...)
+		meta.middleware = funcNames(mws)
+		chain = append(chain, mws...)
+		chain = append(chain, handler)
+
+		for _, method := range r.methods {
+			a.engine.Handle(method, fullPath, chain...)
+			a.setRouteMeta(a.engine, method, fullPath, meta)
+			if scope.version != "" && a.versionRouter != nil {
+				a.versionRouter.add(method, joinPaths(scope.public, r.path), scope.version, scope.versionAt)
+			}
+		}
+	}
+
+	for _, child := range group.children {
+		a.registerScope(child, scope)
+	}
+}
+
+// joinPaths 与 gin 拼接分组路径的规则一致（保留结尾的 /）
+func joinPaths(absolutePath, relativePath string) string {
+	if relativePath == "" {
+		return absolutePath
+	}
+	finalPath := path.Join(absolutePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(finalPath, "/") {
+		return finalPath + "/"
+	}
+	return finalPath
+}
@@ -0,0 +1,109 @@
+package nomoyu_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/nomoyu"
+	"github.com/nomoyu/go-gin-framework/nomoyu/nomoyutest"
+)
+
+func tagHeader(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("X-"+name, "1")
+		c.Next()
+	}
+}
+
+func fullPath(c *gin.Context) { c.String(http.StatusOK, c.FullPath()) }
+
+func TestNestedGroups(t *testing.T) {
+	app := nomoyutest.New(t, nomoyutest.WithConfig("auth:\n  enabled: true\n  mode: jwt\n  jwt:\n    secret: abc\n"),
+		nomoyutest.WithApp(func(a *nomoyu.App) {
+			a.WithRoute(
+				nomoyu.NewGroup("/api").Use(tagHeader("A")).RequireAuth().
+					GET("/login", fullPath, nomoyu.Public()).
+					PATCH("/p", fullPath).
+					Group("/users", func(g nomoyu.RouteGroup) nomoyu.RouteGroup {
+						return g.Use(tagHeader("U")).
+							GET("/:id", fullPath, nomoyu.WithMiddleware(tagHeader("R"))).
+							Group("/deep/", func(g nomoyu.RouteGroup) nomoyu.RouteGroup { return g.Any("x", fullPath, nomoyu.Public()) })
+					}),
+				nomoyu.NewGroup("/").
+					Match([]string{http.MethodGet, http.MethodHead}, "/m", fullPath).
+					OPTIONS("/o", fullPath, nomoyu.AuthRequired()))
+		}))
+	bearer := nomoyutest.WithBearer(app.MintJWT("1", "a"))
+	do := func(method, path string, opts ...nomoyutest.RequestOption) *http.Response {
+		return app.Do(nomoyutest.NewRequest(t, method, path, nil, opts...)).Result()
+	}
+
+	rec := app.GET("/api/login")
+	nomoyutest.AssertStatus(t, rec, http.StatusOK)
+	if rec.Header().Get("X-A") != "1" {
+		t.Fatal("group middleware not applied")
+	}
+
+	nomoyutest.AssertStatus(t, app.GET("/api/users/1"), http.StatusUnauthorized)
+	rec = app.GET("/api/users/1", bearer)
+	nomoyutest.AssertStatus(t, rec, http.StatusOK)
+	if rec.Header().Get("X-A") != "1" || rec.Header().Get("X-U") != "1" || rec.Header().Get("X-R") != "1" {
+		t.Fatalf("middleware chain: %v", rec.Header())
+	}
+	if rec.Body.String() != "/api/users/:id" {
+		t.Fatalf("full path %q", rec.Body.String())
+	}
+
+	for _, tc := range []struct {
+		method, path string
+		opts         []nomoyutest.RequestOption
+		status       int
+	}{
+		{http.MethodDelete, "/api/users/deep/x", nil, http.StatusOK},
+		{http.MethodPatch, "/api/p", nil, http.StatusUnauthorized},
+		{http.MethodPatch, "/api/p", []nomoyutest.RequestOption{bearer}, http.StatusOK},
+		{http.MethodHead, "/m", nil, http.StatusOK},
+		{http.MethodPost, "/m", nil, http.StatusNotFound},
+		{http.MethodOptions, "/o", nil, http.StatusUnauthorized},
+	} {
+		if got := do(tc.method, tc.path, tc.opts...).StatusCode; got != tc.status {
+			t.Errorf("%s %s: got %d, want %d", tc.method, tc.path, got, tc.status)
+		}
+	}
+
+	routes := map[string]nomoyu.RouteInfo{}
+	for _, r := range app.Routes() {
+		routes[r.Method+" "+r.Path] = r
+	}
+	if r := routes["GET /api/users/:id"]; !r.RequireAuth || r.Group != "/api/users" {
+		t.Errorf("GET /api/users/:id: %+v", r)
+	}
+	if r := routes["PUT /api/users/deep/x"]; r.RequireAuth {
+		t.Errorf("public Any route requires auth: %+v", r)
+	}
+}
+
+func TestRouteGroupBranches(t *testing.T) {
+	// 从同一个分组派生的声明互不影响
+	base := nomoyu.NewGroup("/api").GET("/base", fullPath)
+	a := base.Use(tagHeader("A")).GET("/a", fullPath)
+	b := base.Use(tagHeader("B")).POST("/b", fullPath)
+	child := nomoyu.NewGroup("/v").Group("/x", func(g nomoyu.RouteGroup) nomoyu.RouteGroup { return g.GET("", fullPath) })
+	_ = child.Group("/y", func(g nomoyu.RouteGroup) nomoyu.RouteGroup { return g.GET("", fullPath) })
+
+	app := nomoyutest.New(t, nomoyutest.WithApp(func(app *nomoyu.App) { app.WithRoute(a, child) }))
+	rec := app.GET("/api/a")
+	nomoyutest.AssertStatus(t, rec, http.StatusOK)
+	if rec.Header().Get("X-A") != "1" || rec.Header().Get("X-B") != "" {
+		t.Fatalf("middleware of /api/a: %v", rec.Header())
+	}
+	nomoyutest.AssertStatus(t, app.GET("/api/base"), http.StatusOK)
+	nomoyutest.AssertStatus(t, app.POST("/api/b", nil), http.StatusNotFound)
+	nomoyutest.AssertStatus(t, app.GET("/v/x"), http.StatusOK)
+	nomoyutest.AssertStatus(t, app.GET("/v/y"), http.StatusNotFound)
+
+	app = nomoyutest.New(t, nomoyutest.WithApp(func(app *nomoyu.App) { app.WithRoute(b) }))
+	nomoyutest.AssertStatus(t, app.POST("/api/b", nil), http.StatusOK)
+	nomoyutest.AssertStatus(t, app.GET("/api/a"), http.StatusNotFound)
+}
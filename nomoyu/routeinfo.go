@@ -0,0 +1,220 @@
+package nomoyu
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+	"github.com/nomoyu/go-gin-framework/pkg/response"
+)
+
+const routesPath = "/debug/routes"
+
+// RouteInfo 已挂载路由的描述信息
+type RouteInfo struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	Handler     string   `json:"handler"`
+	RequireAuth bool     `json:"require_auth"`         // 是否实际挂载了认证中间件
+	Middleware  []string `json:"middleware,omitempty"` // 分组中间件（不含全局中间件）
+	Group       string   `json:"group,omitempty"`      // 所属 RouteGroup 前缀
+	Module      string   `json:"module,omitempty"`     // 由哪个模块注册
+	Admin       bool     `json:"admin,omitempty"`      // 是否挂在管理端口上
+	Summary     string   `json:"summary,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Version     string   `json:"version,omitempty"`
+	PublicPath  string   `json:"public_path,omitempty"` // 按请求头/Accept 声明版本时客户端请求的路径（不含版本段）
+	Deprecated  bool     `json:"deprecated,omitempty"`
+	Protocol    string   `json:"protocol,omitempty"` // websocket / sse 长连接路由
+
+	// 由 Handle 创建的类型化 handler 的请求/响应类型
+	Request      reflect.Type `json:"-"`
+	Response     reflect.Type `json:"-"`
+	RequestType  string       `json:"request,omitempty"`
+	ResponseType string       `json:"response,omitempty"`
+}
+
+// routeMeta gin.RouteInfo 之外、由框架记录的路由信息
+type routeMeta struct {
+	requireAuth bool
+	middleware  []string
+	group       string
+	module      string
+	types       *HandlerTypes
+	summary     string
+	description string
+	tags        []string
+	version     string
+	publicPath  string
+	deprecated  bool
+	handler     string // 框架包装过的 handler 记录原始函数名
+	protocol    string
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
+
+// trackRoutes 执行 register，并把期间新增到 engine 上的路由标记为 meta
+func (a *App) trackRoutes(engine *gin.Engine, meta routeMeta, register func()) {
+	before := make(map[string]struct{})
+	for _, r := range engine.Routes() {
+		before[routeKey(r.Method, r.Path)] = struct{}{}
+	}
+	register()
+
+	for _, r := range engine.Routes() {
+		if _, ok := before[routeKey(r.Method, r.Path)]; !ok {
+			a.setRouteMeta(engine, r.Method, r.Path, meta)
+		}
+	}
+}
+
+func (a *App) setRouteMeta(engine *gin.Engine, method, path string, meta routeMeta) {
+	if a.routeMetas == nil {
+		a.routeMetas = make(map[*gin.Engine]map[string]routeMeta)
+	}
+	metas := a.routeMetas[engine]
+	if metas == nil {
+		metas = make(map[string]routeMeta)
+		a.routeMetas[engine] = metas
+	}
+	if engine == a.engine && a.globalAuth {
+		meta.requireAuth = true
+	}
+	metas[routeKey(method, path)] = meta
+}
+
+// Routes 返回业务端与管理端已挂载的全部路由（Build 之后调用），按路径、方法排序
+func (a *App) Routes() []RouteInfo {
+	routes := a.engineRoutes(a.engine, false)
+	if a.adminEngine != nil {
+		routes = append(routes, a.engineRoutes(a.adminEngine, true)...)
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Admin != routes[j].Admin {
+			return !routes[i].Admin
+		}
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+func (a *App) engineRoutes(engine *gin.Engine, admin bool) []RouteInfo {
+	metas := a.routeMetas[engine]
+	var routes []RouteInfo
+	for _, r := range engine.Routes() {
+		meta := metas[routeKey(r.Method, r.Path)]
+		info := RouteInfo{
+			Method:      r.Method,
+			Path:        r.Path,
+			Handler:     r.Handler,
+			RequireAuth: meta.requireAuth,
+			Middleware:  meta.middleware,
+			Group:       meta.group,
+			Module:      meta.module,
+			Admin:       admin,
+			Summary:     meta.summary,
+			Description: meta.description,
+			Tags:        meta.tags,
+			Version:     meta.version,
+			PublicPath:  meta.publicPath,
+			Deprecated:  meta.deprecated,
+			Protocol:    meta.protocol,
+		}
+		if meta.handler != "" {
+			info.Handler = meta.handler
+		}
+		if t := meta.types; t != nil {
+			info.Handler = t.Name
+			info.Request, info.Response = t.Request, t.Response
+			info.RequestType, info.ResponseType = t.Request.String(), t.Response.String()
+		}
+		routes = append(routes, info)
+	}
+	return routes
+}
+
+// isDevEnv app.env 为空或 dev
+func isDevEnv() bool {
+	env := config.Current().App.Env
+	return env == "" || env == "dev"
+}
+
+// printRoutes 开发环境启动时在 Banner 之后打印路由表
+func (a *App) printRoutes() {
+	if gin.Mode() == gin.TestMode || !isDevEnv() {
+		return
+	}
+	writeRouteTable(os.Stdout, a.Routes())
+}
+
+func writeRouteTable(out io.Writer, routes []RouteInfo) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	// 颜色控制符会被 tabwriter 计入列宽，只给最后一列上色
+	fmt.Fprintln(w, "METHOD\tPATH\tAUTH\tMIDDLEWARE\tHANDLER")
+	for _, r := range routes {
+		auth := "-"
+		if r.RequireAuth {
+			auth = "✔"
+		}
+		mw := "-"
+		if len(r.Middleware) > 0 {
+			short := make([]string, len(r.Middleware))
+			for i, m := range r.Middleware {
+				short[i] = shortFuncName(m)
+			}
+			mw = strings.Join(short, ",")
+		}
+		path := r.Path
+		if r.Admin {
+			path += " (admin)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s%s%s\n",
+			r.Method, path, auth, mw, clGray, shortFuncName(r.Handler), clReset)
+	}
+	_ = w.Flush()
+	fmt.Fprintln(out)
+}
+
+// registerRoutesRoute 以 JSON 提供路由表：开启管理端口时挂在管理端，否则只在开发环境挂在业务端
+func (a *App) registerRoutesRoute(r *gin.Engine) {
+	r.GET(routesPath, func(c *gin.Context) {
+		response.Success(c, a.Routes())
+	})
+}
+
+// funcName 返回函数的完整名称（与 gin.RouteInfo.Handler 一致）
+func funcName(f interface{}) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
+
+func funcNames(handlers []gin.HandlerFunc) []string {
+	if len(handlers) == 0 {
+		return nil
+	}
+	names := make([]string, len(handlers))
+	for i, h := range handlers {
+		names[i] = funcName(h)
+	}
+	return names
+}
+
+// shortFuncName 去掉包路径，如 github.com/x/y/user.(*Handler).Get-fm -> user.(*Handler).Get-fm
+func shortFuncName(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
@@ -0,0 +1,151 @@
+package nomoyu_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/nomoyu"
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+	"github.com/nomoyu/go-gin-framework/pkg/response"
+)
+
+// loadEnvConfig 与 loadTestConfig 相同，但使用给定的 app.env
+func loadEnvConfig(t *testing.T, env string) {
+	t.Helper()
+	loadTestConfig(t)
+	if err := config.LoadBytes([]byte(fmt.Sprintf("app: {name: m, env: %s}\nlog: {level: error, path: %q}\n", env, t.TempDir()))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func ping(c *gin.Context) { c.String(http.StatusOK, "pong") }
+
+func TestDebugRoutesOnMainEngine(t *testing.T) {
+	loadEnvConfig(t, "dev")
+	app := nomoyu.New().WithRoute(nomoyu.NewGroup("/api").GET("/ping", ping))
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	app.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/routes", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /debug/routes: %d", w.Code)
+	}
+	var out struct {
+		response.Response
+		Data []nomoyu.RouteInfo `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, r := range out.Data {
+		if r.Method == http.MethodGet && r.Path == "/api/ping" {
+			found = true
+			if r.Group != "/api" || r.RequireAuth || !strings.HasSuffix(r.Handler, ".ping") {
+				t.Fatalf("route = %+v", r)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("GET /api/ping missing from %s", w.Body)
+	}
+}
+
+func TestDebugRoutesNotOnMainEngine(t *testing.T) {
+	cases := []struct {
+		name  string
+		env   string
+		admin bool
+	}{
+		{name: "prod", env: "prod"},
+		{name: "dev with admin server", env: "dev", admin: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			loadEnvConfig(t, tc.env)
+			app := nomoyu.New()
+			if tc.admin {
+				app.WithAdminServer("127.0.0.1:0")
+			}
+			if err := app.Build(); err != nil {
+				t.Fatal(err)
+			}
+			w := httptest.NewRecorder()
+			app.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/routes", nil))
+			if w.Code != http.StatusNotFound {
+				t.Fatalf("GET /debug/routes on main engine: %d", w.Code)
+			}
+		})
+	}
+}
+
+// captureStdout 返回 f 执行期间写到标准输出的内容
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan []byte)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- out
+	}()
+	func() {
+		defer func() { os.Stdout = stdout }()
+		f()
+	}()
+	w.Close()
+	return string(<-done)
+}
+
+func TestPrintRoutes(t *testing.T) {
+	cases := []struct {
+		env   string
+		print bool
+	}{
+		{env: "dev", print: true},
+		{env: "prod", print: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.env, func(t *testing.T) {
+			loadEnvConfig(t, tc.env)
+			// 测试模式下不打印，这里切回 debug 模式
+			gin.SetMode(gin.DebugMode)
+			debugWriter := gin.DefaultWriter
+			gin.DefaultWriter = io.Discard
+			t.Cleanup(func() {
+				gin.SetMode(gin.TestMode)
+				gin.DefaultWriter = debugWriter
+			})
+
+			app := nomoyu.New().WithRoute(nomoyu.NewGroup("/api").GET("/ping", ping))
+			var err error
+			out := captureStdout(t, func() { err = app.Build() })
+			if err != nil {
+				t.Fatal(err)
+			}
+			header := strings.Contains(out, "METHOD  PATH")
+			var line bool
+			for _, l := range strings.Split(out, "\n") {
+				if f := strings.Fields(l); len(f) == 5 && f[0] == "GET" && f[1] == "/api/ping" && f[2] == "-" {
+					line = strings.Contains(f[4], "nomoyu_test.ping")
+				}
+			}
+			if header != tc.print || line != tc.print {
+				t.Fatalf("route table printed = %v/%v, want %v\n%s", header, line, tc.print, out)
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package nomoyu
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/pkg/config"
 	"github.com/nomoyu/go-gin-framework/pkg/logger"
 	"github.com/nomoyu/go-gin-framework/pkg/scheduler"
 )
@@ -35,41 +38,65 @@ func (a *App) WithCronTasks(tasks ...scheduler.Task) *App {
 	return a
 }
 
-func (a *App) startScheduler() {
-	if a.scheduler == nil {
-		return
-	}
+// schedulerModule 内置定时任务模块（模块名 "scheduler"）：Start 时启动所有调度器，Stop 时等待任务结束
+type schedulerModule struct {
+	opt *SchedulerOption
+}
 
+func (m *schedulerModule) Name() string           { return "scheduler" }
+func (m *schedulerModule) Register(r *gin.Engine) {}
+
+func (m *schedulerModule) Init(ctx context.Context, app *App) error {
 	// 至少保留一个调度器，用于挂载框架内配置的定时任务。
-	if len(a.scheduler.Instances) == 0 || a.scheduler.Instances[0] == nil {
-		a.scheduler.Instances = append([]*scheduler.Scheduler{scheduler.New()}, a.scheduler.Instances...)
+	if len(m.opt.Instances) == 0 || m.opt.Instances[0] == nil {
+		m.opt.Instances = append([]*scheduler.Scheduler{scheduler.New()}, m.opt.Instances...)
 	}
 
 	// 框架层注册的任务挂到第一个调度器，其余调度器按用户预置任务启动。
-	if first := a.scheduler.Instances[0]; first != nil {
-		for _, task := range a.scheduler.Tasks {
-			if _, err := first.AddTask(task); err != nil {
-				logger.Errorf("register cron task %s failed: %v", task.Name, err)
-			}
+	first := m.opt.Instances[0]
+	for _, task := range m.opt.Tasks {
+		if _, err := first.AddTask(task); err != nil {
+			return fmt.Errorf("register cron task %s failed: %w", task.Name, err)
 		}
 	}
 
-	for _, instance := range a.scheduler.Instances {
+	// 配置 scheduler.disabled 暂停的任务，随配置热更新
+	m.setDisabled(config.Current().Scheduler.Disabled)
+	app.subscribeConfig(func() func() {
+		return config.OnChange("scheduler", func(_, conf config.Scheduler) {
+			m.setDisabled(conf.Disabled)
+			logger.Infof("config reload: disabled cron tasks %v", conf.Disabled)
+		})
+	})
+	return nil
+}
+
+func (m *schedulerModule) setDisabled(names []string) {
+	for _, instance := range m.opt.Instances {
+		if instance != nil {
+			instance.SetDisabled(names...)
+		}
+	}
+}
+
+func (m *schedulerModule) Start(ctx context.Context) error {
+	for _, instance := range m.opt.Instances {
 		if instance == nil {
 			continue
 		}
 		instance.Start()
 	}
+	return nil
+}
 
-	a.OnShutdown(func(ctx context.Context) error {
-		for _, instance := range a.scheduler.Instances {
-			if instance == nil {
-				continue
-			}
-			if err := instance.Stop(ctx); err != nil {
-				return err
-			}
+func (m *schedulerModule) Stop(ctx context.Context) error {
+	for _, instance := range m.opt.Instances {
+		if instance == nil {
+			continue
 		}
-		return nil
-	})
+		if err := instance.Stop(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -3,119 +3,249 @@ package nomoyu
 import (
 	"context"
 	"fmt"
-	"github.com/nomoyu/go-gin-framework/internal/middleware"
 	"github.com/nomoyu/go-gin-framework/pkg/config"
 	"github.com/nomoyu/go-gin-framework/pkg/logger"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"sync/atomic"
 	"syscall"
 	"time"
 )
 
+// ServerOption http.Server 参数，未设置（nil/零值）的字段依次回退到配置文件 server.* 与框架默认值；
+// 超时字段为指针，用 Timeout(0) 显式关闭某项超时
 type ServerOption struct {
-	Port     string
-	FromUser bool
+	Host              string
+	Port              int
+	ReadTimeout       *time.Duration
+	WriteTimeout      *time.Duration
+	IdleTimeout       *time.Duration
+	ReadHeaderTimeout *time.Duration // 防 slowloris，为 0 时沿用 ReadTimeout
+	MaxHeaderBytes    int            // 为 0 时使用 http.DefaultMaxHeaderBytes(1MB)
+	ShutdownTimeout   *time.Duration
+	UnixSocket        string      // 非空时监听 Unix domain socket 而不是 host:port
+	SocketMode        os.FileMode // Unix socket 文件权限，如 0660
+	FromUser          bool
 }
 
-func (a *App) Run(addr ...string) {
-	// 注册模块
-	for _, m := range a.modules {
-		m.Register(a.engine)
+// Timeout 返回 d 的指针，用于设置 ServerOption 的超时字段（0 表示不限制）
+func Timeout(d time.Duration) *time.Duration {
+	return &d
+}
+
+// WithServer 手动指定监听地址与超时参数（优先级高于配置文件）
+func (a *App) WithServer(opt ServerOption) *App {
+	opt.FromUser = true
+	a.serverOption = &opt
+	return a
+}
+
+// resolveServerOption 合并 WithServer > 配置 server.*（默认值见 config.Server 的 default 标签），
+// 返回的超时字段均不为 nil
+func resolveServerOption(a *App) *ServerOption {
+	conf := config.Current().Server
+	opt := ServerOption{}
+	if a.serverOption != nil {
+		opt = *a.serverOption
 	}
 
-	// 注册路由分组
-	for _, group := range a.routes {
-		g := a.engine.Group(group.prefix)
-		// ✅ 如果启用了权限认证模块并且该路由声明了 RequireAuth
-		if group.requireAuth && a.authOption != nil {
-			g.Use(middleware.AuthMiddleware(a.authOption.Strategy))
-		}
-		if len(group.middleware) > 0 {
-			g.Use(group.middleware...)
-		}
-		for _, register := range group.routes {
-			register(g)
+	opt.Host = firstNonZero(opt.Host, conf.Host)
+	opt.Port = firstNonZero(opt.Port, conf.Port)
+	opt.ReadTimeout = timeoutOr(opt.ReadTimeout, conf.ReadTimeout)
+	opt.WriteTimeout = timeoutOr(opt.WriteTimeout, conf.WriteTimeout)
+	opt.IdleTimeout = timeoutOr(opt.IdleTimeout, conf.IdleTimeout)
+	opt.ReadHeaderTimeout = timeoutOr(opt.ReadHeaderTimeout, conf.ReadHeaderTimeout)
+	opt.MaxHeaderBytes = firstNonZero(opt.MaxHeaderBytes, conf.MaxHeaderBytes)
+	opt.ShutdownTimeout = timeoutOr(opt.ShutdownTimeout, conf.ShutdownTimeout)
+	opt.UnixSocket = firstNonZero(opt.UnixSocket, conf.UnixSocket)
+	if opt.SocketMode == 0 && conf.SocketMode != "" {
+		// 格式已在加载配置时校验
+		mode, _ := strconv.ParseUint(conf.SocketMode, 8, 32)
+		opt.SocketMode = os.FileMode(mode)
+	}
+
+	a.serverOption = &opt
+	return a.serverOption
+}
+
+// timeoutOr 代码中设置过（包括 0）的超时优先，否则使用配置值
+func timeoutOr(v *time.Duration, conf time.Duration) *time.Duration {
+	if v != nil {
+		return v
+	}
+	return &conf
+}
+
+func firstNonZero[T comparable](vs ...T) T {
+	var zero T
+	for _, v := range vs {
+		if v != zero {
+			return v
 		}
 	}
+	return zero
+}
+
+// Run 构建并启动应用，阻塞直到收到退出信号；初始化或监听失败时返回错误
+//
+// addr 支持 host:port 与 unix:///path/to/app.sock
+func (a *App) Run(addr ...string) error {
+	if err := a.Build(); err != nil {
+		return err
+	}
 
-	// 端口优先级：传参 > 配置 > 默认
-	port := ":3303"
+	// 地址优先级：传参 > WithServer > 配置 > 默认
+	opt := resolveServerOption(a)
+	port := net.JoinHostPort(opt.Host, strconv.Itoa(opt.Port))
+	if opt.UnixSocket != "" {
+		port = unixScheme + opt.UnixSocket
+	}
 	if len(addr) > 0 && addr[0] != "" {
 		port = addr[0]
-	} else if config.Conf.Server.Port != 0 {
-		port = fmt.Sprintf(":%d", config.Conf.Server.Port)
 	}
+	return a.serve(port, nil)
+}
 
-	// ✅ 默认停机超时（可再做 WithShutdownTimeout 扩展）
-	if a.shutdownTimeout == 0 {
-		a.shutdownTimeout = 10 * time.Second
+// RunListener 在调用方创建好的 listener 上启动应用（如测试、自定义 socket），其余流程与 Run 相同
+func (a *App) RunListener(ln net.Listener) error {
+	if err := a.Build(); err != nil {
+		return err
 	}
+	resolveServerOption(a)
+	return a.serve(ln.Addr().String(), ln)
+}
 
-	// ✅ 启动定时任务调度（若已配置）
-	a.startScheduler()
+// serve 启动模块与 HTTP 服务并阻塞到退出；ln 为 nil 时按 port 监听
+func (a *App) serve(port string, ln net.Listener) error {
+	atomic.StoreInt32(&a.serving, 1)
+	defer close(a.served)
+	opt := a.serverOption
+	a.shutdownTimeout = *opt.ShutdownTimeout
+
+	// ✅ 按依赖顺序启动模块（定时任务等），失败时回滚已启动的模块
+	if err := a.startModules(context.Background()); err != nil {
+		a.shutdown()
+		return fmt.Errorf("nomoyu: %w", err)
+	}
 
-	// ✅ 关机防护中间件（建议放最前或日志/trace之后）
-	a.engine.Use(a.shutdownGuard())
+	// TLS：WithTLS 或配置 server.tls
+	tlsConfig, err := a.buildTLSConfig()
+	if err != nil {
+		a.shutdown()
+		return fmt.Errorf("nomoyu: init tls: %w", err)
+	}
+	// 明文 HTTP/2：WithH2C 或配置 server.h2c
+	handler := a.Handler()
+	if tlsConfig == nil && (a.h2c || config.Current().Server.H2C) {
+		handler = h2c.NewHandler(handler, &http2.Server{})
+		logger.Info("h2c enabled")
+	}
 
 	// 用 http.Server 承载，支持优雅停机
 	a.httpServer = &http.Server{
-		Addr:         port,
-		Handler:      a.engine,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 30 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		Addr:              port,
+		Handler:           handler,
+		TLSConfig:         tlsConfig,
+		ReadTimeout:       *opt.ReadTimeout,
+		WriteTimeout:      *opt.WriteTimeout,
+		IdleTimeout:       *opt.IdleTimeout,
+		ReadHeaderTimeout: *opt.ReadHeaderTimeout,
+		MaxHeaderBytes:    opt.MaxHeaderBytes,
+		ConnState:         a.trackConnState,
 	}
 
-	// 启动服务（异步）
-	errCh := make(chan error, 1)
-	go func() {
-		logger.Infof("server listening on %s", port)
-		if err := a.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			errCh <- err
-			return
+	if ln == nil {
+		if ln, err = a.listen(listenerMain, port); err != nil {
+			a.shutdown()
+			return fmt.Errorf("nomoyu: listen %s: %w", port, err)
 		}
-		errCh <- nil
-	}()
+	} else {
+		a.listeners = append(a.listeners, namedListener{name: listenerMain, ln: ln})
+	}
 
-	// 捕获信号（Ctrl+C / 容器 SIGTERM）
+	// 捕获信号（Ctrl+C / 容器 SIGTERM）：在开始接受请求前注册，就绪后收到的信号都走优雅停机
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(quit)
+	// 平滑重启信号（SIGHUP / SIGUSR2），未开启时 restart 永远不会触发
+	restart := make(chan os.Signal, 1)
+	if a.gracefulRestartEnabled() {
+		signal.Notify(restart, restartSignals()...)
+		defer signal.Stop(restart)
+	}
 
-	select {
-	case sig := <-quit:
-		logger.Warnf("received signal: %v, start graceful shutdown...", sig)
-	case err := <-errCh:
-		if err != nil {
-			logger.Errorf("http server error: %v", err)
+	errCh := make(chan error, 2)
+	go func() {
+		var err error
+		if tlsConfig != nil {
+			logger.Infof("server listening on %s (https)", port)
+			// 证书由 TLSConfig.GetCertificate 提供，ServeTLS 会自动协商 HTTP/2
+			err = a.httpServer.ServeTLS(ln, "", "")
 		} else {
-			logger.Infof("http server exited")
+			logger.Infof("server listening on %s", port)
+			err = a.httpServer.Serve(ln)
 		}
-	}
-
-	// 标记进入关机，拒绝新请求
-	atomic.StoreInt32(&a.shutting, 1)
-
-	// 关机超时上下文
-	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
-	defer cancel()
+		if err != nil && err != http.ErrServerClosed {
+			errCh <- err
+			return
+		}
+		errCh <- nil
+	}()
 
-	// 优雅关闭 HTTP
-	if a.httpServer != nil {
-		if err := a.httpServer.Shutdown(ctx); err != nil {
-			logger.Errorf("http server shutdown error: %v", err)
-		} else {
-			logger.Infof("http server shutdown gracefully")
+	// 管理端口（若开启）独立监听，与业务端口一起优雅停机
+	if a.adminEngine != nil {
+		a.adminServer = a.newAdminServer()
+		adminLn, err := a.listen(listenerAdmin, a.adminServer.Addr)
+		if err != nil {
+			a.shutdown()
+			return fmt.Errorf("nomoyu: listen admin %s: %w", a.adminServer.Addr, err)
 		}
+		go func() {
+			logger.Infof("admin server listening on %s", a.adminServer.Addr)
+			if err := a.adminServer.Serve(adminLn); err != nil && err != http.ErrServerClosed {
+				errCh <- fmt.Errorf("admin server: %w", err)
+			}
+		}()
 	}
 
-	// 依次执行清理钩子（倒序更合理）
-	for i := len(a.shutdownHooks) - 1; i >= 0; i-- {
-		if err := a.shutdownHooks[i](ctx); err != nil {
-			logger.Warnf("shutdown hook error: %v", err)
+	a.notifyReady()
+
+	var serveErr error
+wait:
+	for {
+		select {
+		case sig := <-quit:
+			logger.Warnf("received signal: %v, start graceful shutdown...", sig)
+			break wait
+		case <-a.stop:
+			logger.Warnf("Shutdown called, start graceful shutdown...")
+			break wait
+		case sig := <-restart:
+			logger.Warnf("received signal: %v, start graceful restart...", sig)
+			if err := a.handoff(); err != nil {
+				logger.Errorf("graceful restart failed, keep serving: %v", err)
+				continue
+			}
+			// 监听 socket 已由新进程接管，无需等待负载均衡摘流量
+			a.stopAccepting(handoffAcceptWait)
+			a.drainDelay = 0
+			logger.Infof("new process is ready, start graceful shutdown...")
+			break wait
+		case serveErr = <-errCh:
+			if serveErr != nil {
+				logger.Errorf("http server error: %v", serveErr)
+			} else {
+				logger.Infof("http server exited")
+			}
+			break wait
 		}
 	}
 
-	logger.Infof("graceful shutdown done, bye 👋")
+	a.shutdown()
+	return serveErr
 }
@@ -0,0 +1,55 @@
+package nomoyu_test
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/nomoyu"
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+)
+
+func TestServerWriteTimeoutPrecedence(t *testing.T) {
+	cases := []struct {
+		name string
+		conf time.Duration // 配置 server.write_timeout，0 表示未配置（默认 30s）
+		opt  *nomoyu.ServerOption
+		ok   bool
+	}{
+		{name: "config", conf: 100 * time.Millisecond, ok: false},
+		{name: "option unset falls back to config", conf: 100 * time.Millisecond, opt: &nomoyu.ServerOption{Host: "127.0.0.1"}, ok: false},
+		{name: "option over config", opt: &nomoyu.ServerOption{WriteTimeout: nomoyu.Timeout(100 * time.Millisecond)}, ok: false},
+		{name: "option zero disables timeout", conf: 100 * time.Millisecond, opt: &nomoyu.ServerOption{WriteTimeout: nomoyu.Timeout(0)}, ok: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			loadTestConfig(t)
+			if tc.conf > 0 {
+				conf := *config.Current()
+				conf.Server.WriteTimeout = tc.conf
+				config.Set(&conf)
+			}
+			app := nomoyu.New().WithRoute(nomoyu.NewGroup("/api").GET("/slow", func(c *gin.Context) {
+				time.Sleep(300 * time.Millisecond)
+				c.String(http.StatusOK, "done")
+			}))
+			if tc.opt != nil {
+				app.WithServer(*tc.opt)
+			}
+			addr := freeAddr(t)
+			startApp(t, app, addr)
+
+			// 超过 WriteTimeout 后服务端写响应失败并断开连接
+			resp, err := http.Get("http://" + addr + "/api/slow")
+			if err == nil {
+				_, err = io.ReadAll(resp.Body)
+				resp.Body.Close()
+			}
+			if (err == nil) != tc.ok {
+				t.Fatalf("err = %v, want ok=%v", err, tc.ok)
+			}
+		})
+	}
+}
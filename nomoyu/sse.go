@@ -0,0 +1,99 @@
+package nomoyu
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+	"github.com/nomoyu/go-gin-framework/pkg/logger"
+	"github.com/nomoyu/go-gin-framework/pkg/redisx"
+	"github.com/nomoyu/go-gin-framework/pkg/sse"
+)
+
+const protocolSSE = "sse"
+
+// SSEOption SSE 参数，零值字段回退到配置 sse.* 与 pkg/sse 默认值
+type SSEOption struct {
+	Heartbeat    time.Duration
+	ReplaySize   int
+	ReplayTTL    time.Duration
+	ClientBuffer int
+	Retry        time.Duration
+	Redis        bool   // 通过 redisx 的 pub/sub 在多实例间广播
+	RedisChannel string // 默认 nomoyu:sse
+	FromUser     bool
+}
+
+// WithSSE 手动指定 SSE 参数（优先级高于配置文件）
+func (a *App) WithSSE(opts ...func(o *SSEOption)) *App {
+	opt := &SSEOption{FromUser: true}
+	for _, fn := range opts {
+		fn(opt)
+	}
+	a.sseOption = opt
+	return a
+}
+
+// SSE 注册 Server-Sent Events 路由：handler 中订阅主题，之后由应用的 Broker（见 App.SSEBroker）持续推送，
+// 客户端带 Last-Event-ID 重连时补发错过的事件；handler 返回错误时按错误码响应
+func (rg RouteGroup) SSE(path string, handler sse.Handler, opts ...RouteOption) RouteGroup {
+	return rg.addRoute(routeSpec{methods: []string{http.MethodGet}, path: path, sse: handler}, opts)
+}
+
+// SSEBroker 返回应用的 SSE Broker（用于在业务代码中 Publish），需在配置加载后调用
+func (a *App) SSEBroker() *sse.Broker {
+	if a.sseBroker == nil {
+		opt := resolveSSEOption(a)
+		a.sseBroker = sse.NewBroker(sse.Options{
+			Heartbeat:    opt.Heartbeat,
+			ReplaySize:   opt.ReplaySize,
+			ReplayTTL:    opt.ReplayTTL,
+			ClientBuffer: opt.ClientBuffer,
+			Retry:        opt.Retry,
+		})
+		if opt.Redis {
+			a.sseBroker.WithFanout(sse.NewRedisFanout(redisx.Client, opt.RedisChannel))
+		}
+	}
+	return a.sseBroker
+}
+
+// resolveSSEOption 合并 WithSSE > 配置 sse.*
+func resolveSSEOption(a *App) SSEOption {
+	opt := SSEOption{}
+	if a.sseOption != nil {
+		opt = *a.sseOption
+	}
+	if config.Current() != nil {
+		conf := config.Current().SSE
+		opt.Heartbeat = firstNonZero(opt.Heartbeat, conf.Heartbeat)
+		opt.ReplaySize = firstNonZero(opt.ReplaySize, conf.ReplaySize)
+		opt.ReplayTTL = firstNonZero(opt.ReplayTTL, conf.ReplayTTL)
+		opt.ClientBuffer = firstNonZero(opt.ClientBuffer, conf.ClientBuffer)
+		opt.Retry = firstNonZero(opt.Retry, conf.Retry)
+		opt.Redis = opt.Redis || conf.Redis
+		opt.RedisChannel = firstNonZero(opt.RedisChannel, conf.RedisChannel)
+	}
+	return opt
+}
+
+func (a *App) sseHandler(handler sse.Handler) gin.HandlerFunc {
+	broker := a.SSEBroker()
+	return func(c *gin.Context) {
+		var user map[string]interface{}
+		if v, ok := c.Get("AuthInfo"); ok {
+			user, _ = v.(map[string]interface{})
+		}
+		err := broker.Serve(c.Writer, c.Request, user, handler)
+		switch {
+		case err == nil, c.Writer.Written():
+			if errors.Is(err, sse.ErrSlowConsumer) {
+				logger.Warnf("sse client too slow, disconnected: %s", c.Request.URL.Path)
+			}
+		default:
+			failWithError(c, err)
+		}
+	}
+}
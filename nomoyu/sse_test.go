@@ -0,0 +1,146 @@
+package nomoyu_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nomoyu/go-gin-framework/nomoyu"
+	"github.com/nomoyu/go-gin-framework/nomoyu/nomoyutest"
+	"github.com/nomoyu/go-gin-framework/pkg/errorcode"
+	"github.com/nomoyu/go-gin-framework/pkg/sse"
+)
+
+// eventReader 按空行切分事件，事件内的多行以 | 连接
+type eventReader struct {
+	sc *bufio.Scanner
+}
+
+func (r eventReader) next(t *testing.T) string {
+	t.Helper()
+	var lines []string
+	for r.sc.Scan() {
+		if line := r.sc.Text(); line != "" {
+			lines = append(lines, line)
+			continue
+		}
+		if len(lines) > 0 {
+			return strings.Join(lines, "|")
+		}
+	}
+	t.Fatalf("stream ended: %v", r.sc.Err())
+	return ""
+}
+
+// nextData 跳过心跳与 retry，返回下一个带数据的事件
+func (r eventReader) nextData(t *testing.T) string {
+	t.Helper()
+	for {
+		if ev := r.next(t); strings.Contains(ev, "data: ") {
+			return ev
+		}
+	}
+}
+
+func TestSSE(t *testing.T) {
+	app := nomoyutest.New(t, nomoyutest.WithFakeRedis(),
+		nomoyutest.WithConfig("sse:\n  heartbeat: 200ms\n  redis: true\n  retry: 3s\n"),
+		nomoyutest.WithApp(func(a *nomoyu.App) {
+			a.WithRoute(nomoyu.NewGroup("/events").
+				SSE("/feed", func(s *sse.Stream) error {
+					if s.Request.URL.Query().Get("deny") != "" {
+						return errorcode.Forbidden
+					}
+					s.Subscribe("news", "user:1")
+					return nil
+				}))
+		}))
+	srv := httptest.NewUnstartedServer(app.Handler())
+	srv.Config.WriteTimeout = time.Second
+	srv.Start()
+	defer srv.Close()
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	// 订阅前返回错误时按统一响应结构返回
+	denied, err := client.Get(srv.URL + "/events/feed?deny=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	denied.Body.Close()
+	if ct := denied.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatalf("denied content type %q", ct)
+	}
+
+	resp, err := client.Get(srv.URL + "/events/feed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type %q", ct)
+	}
+	events := eventReader{bufio.NewScanner(resp.Body)}
+	if ev := events.next(t); ev != "retry: 3000" {
+		t.Fatalf("first event %q", ev)
+	}
+
+	b := app.SSEBroker()
+	ctx := context.Background()
+	for deadline := time.Now().Add(time.Second); b.Count() == 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	b.Publish(ctx, "news", sse.Event{Event: "news", Data: "line1\nline2"})
+	b.PublishJSON(ctx, "user:1", "notify", map[string]int{"n": 1})
+	b.Publish(ctx, "other", sse.Event{Data: "nope"})
+
+	news := events.nextData(t)
+	if !strings.HasSuffix(news, "|event: news|data: line1|data: line2") {
+		t.Fatalf("news event %q", news)
+	}
+	notify := events.nextData(t)
+	if !strings.HasSuffix(notify, `|event: notify|data: {"n":1}`) {
+		t.Fatalf("notify event %q", notify)
+	}
+
+	// 心跳让连接越过 WriteTimeout 后仍能收到事件
+	time.Sleep(1500 * time.Millisecond)
+	b.Publish(ctx, "news", sse.Event{Data: "after-timeout"})
+	if ev := events.nextData(t); !strings.HasSuffix(ev, "|data: after-timeout") {
+		t.Fatalf("event after write timeout %q", ev)
+	}
+	resp.Body.Close()
+
+	// Last-Event-ID 补发之后的事件
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/events/feed", nil)
+	req.Header.Set("Last-Event-ID", strings.TrimPrefix(strings.Split(news, "|")[0], "id: "))
+	resumed, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resumed.Body.Close()
+	replay := eventReader{bufio.NewScanner(resumed.Body)}
+	if ev := replay.nextData(t); ev != notify {
+		t.Fatalf("replayed %q, want %q", ev, notify)
+	}
+	if ev := replay.nextData(t); !strings.HasSuffix(ev, "|data: after-timeout") {
+		t.Fatalf("replayed %q", ev)
+	}
+
+	// 关闭 broker 时结束事件流
+	done := make(chan struct{})
+	go func() {
+		for replay.sc.Scan() {
+		}
+		close(done)
+	}()
+	b.Close()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream not closed with the broker")
+	}
+}
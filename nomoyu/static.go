@@ -0,0 +1,116 @@
+package nomoyu
+
+import (
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/pkg/response"
+	"github.com/nomoyu/go-gin-framework/pkg/static"
+)
+
+// StaticOption 静态文件挂载参数
+type StaticOption struct {
+	Index     string        // 目录默认文件，默认 index.html
+	SPA       bool          // 前缀下找不到的页面请求回退到 index.html（前端路由）
+	MaxAge    time.Duration // 不带内容哈希的文件的缓存时间，默认 no-cache
+	Immutable func(name string) bool
+	// Exclude 不做 SPA 回退的路径前缀；已注册的路由分组前缀会自动排除
+	Exclude []string
+}
+
+type staticMount struct {
+	prefix string
+	server *static.Server
+	opt    StaticOption
+}
+
+// WithStatic 在 prefix 下提供 fsys 中的静态文件（embed.FS 或 os.DirFS）
+//
+// 文件由 NoRoute 兜底提供，因此可以挂在 "/" 而不与 API 路由冲突；未命中文件时仍返回原有的 404 页面
+func (a *App) WithStatic(prefix string, fsys fs.FS, opts ...func(o *StaticOption)) *App {
+	opt := StaticOption{}
+	for _, fn := range opts {
+		fn(&opt)
+	}
+	a.statics = append(a.statics, staticMount{
+		prefix: joinPaths("/", prefix),
+		server: static.New(fsys, static.Options{Index: opt.Index, MaxAge: opt.MaxAge, Immutable: opt.Immutable}),
+		opt:    opt,
+	})
+	return a
+}
+
+// initStatic 最长前缀优先，并收集 SPA 回退需要排除的 API 前缀
+func initStatic(a *App) {
+	if len(a.statics) == 0 {
+		return
+	}
+	sort.SliceStable(a.statics, func(i, j int) bool {
+		return len(a.statics[i].prefix) > len(a.statics[j].prefix)
+	})
+	seen := map[string]bool{}
+	for _, r := range a.Routes() {
+		if r.Group != "" && r.Group != "/" && !seen[r.Group] {
+			seen[r.Group] = true
+			a.apiPrefixes = append(a.apiPrefixes, r.Group)
+		}
+	}
+}
+
+// noRoute 先尝试静态文件与 SPA 回退，其余请求返回 404 页面
+func (a *App) noRoute(c *gin.Context) {
+	if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+		p := c.Request.URL.Path
+		for _, m := range a.statics {
+			rel, ok := trimPathPrefix(p, m.prefix)
+			if !ok {
+				continue
+			}
+			if m.server.Serve(c.Writer, c.Request, rel) {
+				return
+			}
+			if m.opt.SPA && a.isPageRequest(c.Request, m.opt.Exclude) && m.server.ServeIndex(c.Writer, c.Request) {
+				return
+			}
+			break
+		}
+	}
+	response.NotFound(c, "无法找到您请求的页面")
+}
+
+// isPageRequest 只有浏览器页面跳转才回退到 index.html：
+// 接受 text/html、最后一段没有扩展名（缺失的 .js/.png 仍是 404）、不在 API 前缀下
+func (a *App) isPageRequest(r *http.Request, exclude []string) bool {
+	if !strings.Contains(r.Header.Get("Accept"), "text/html") {
+		return false
+	}
+	if path.Ext(r.URL.Path) != "" {
+		return false
+	}
+	for _, prefix := range append(exclude, a.apiPrefixes...) {
+		if _, ok := trimPathPrefix(r.URL.Path, prefix); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// trimPathPrefix 按路径段匹配前缀：/app 匹配 /app、/app/x，不匹配 /apple
+func trimPathPrefix(p, prefix string) (string, bool) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return p, true
+	}
+	if p == prefix {
+		return "/", true
+	}
+	if strings.HasPrefix(p, prefix+"/") {
+		return p[len(prefix):], true
+	}
+	return "", false
+}
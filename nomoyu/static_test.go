@@ -0,0 +1,84 @@
+package nomoyu_test
+
+import (
+	"net/http"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/nomoyu"
+	"github.com/nomoyu/go-gin-framework/nomoyu/nomoyutest"
+)
+
+func TestStaticSPA(t *testing.T) {
+	const asset = "/assets/app-B7x2k9Qa.js"
+	fsys := fstest.MapFS{
+		"index.html":                {Data: []byte("<html>spa</html>")},
+		"assets/app-B7x2k9Qa.js":    {Data: []byte("console.log(1)")},
+		"assets/app-B7x2k9Qa.js.br": {Data: []byte("BR")},
+		"assets/app-B7x2k9Qa.js.gz": {Data: []byte("GZ")},
+		"logo.png":                  {Data: []byte("png")},
+	}
+	app := nomoyutest.New(t, nomoyutest.WithApp(func(a *nomoyu.App) {
+		a.WithStatic("/", fsys, func(o *nomoyu.StaticOption) { o.SPA = true }).
+			WithRoute(nomoyu.NewGroup("/api").GET("/x", func(c *gin.Context) { c.String(http.StatusOK, "api") }))
+	}))
+	html := nomoyutest.WithHeader("Accept", "text/html,*/*")
+	encoding := func(v string) nomoyutest.RequestOption { return nomoyutest.WithHeader("Accept-Encoding", v) }
+
+	for _, tc := range []struct {
+		path     string
+		opts     []nomoyutest.RequestOption
+		status   int
+		body     string
+		cache    string
+		encoding string
+	}{
+		{"/", nil, http.StatusOK, "<html>spa</html>", "no-cache", ""},
+		{asset, nil, http.StatusOK, "console.log(1)", "public, max-age=31536000, immutable", ""},
+		{asset, []nomoyutest.RequestOption{encoding("gzip, br")}, http.StatusOK, "BR", "public, max-age=31536000, immutable", "br"},
+		{asset, []nomoyutest.RequestOption{encoding("gzip")}, http.StatusOK, "GZ", "public, max-age=31536000, immutable", "gzip"},
+		{"/logo.png", nil, http.StatusOK, "png", "no-cache", ""},
+		// 页面路由回退到 index.html，资源与 API 不回退
+		{"/users/1", []nomoyutest.RequestOption{html}, http.StatusOK, "<html>spa</html>", "no-cache", ""},
+		{"/users/1", nil, http.StatusNotFound, "", "", ""},
+		{"/missing.js", []nomoyutest.RequestOption{html}, http.StatusNotFound, "", "", ""},
+		{"/api/nope", []nomoyutest.RequestOption{html}, http.StatusNotFound, "", "", ""},
+		{"/api/x", nil, http.StatusOK, "api", "", ""},
+	} {
+		rec := app.GET(tc.path, tc.opts...)
+		if rec.Code != tc.status || (tc.body != "" && rec.Body.String() != tc.body) ||
+			rec.Header().Get("Cache-Control") != tc.cache || rec.Header().Get("Content-Encoding") != tc.encoding {
+			t.Errorf("%s %v: got %d %q cache=%q encoding=%q", tc.path, tc.opts != nil, rec.Code, rec.Body.String(),
+				rec.Header().Get("Cache-Control"), rec.Header().Get("Content-Encoding"))
+		}
+	}
+	if vary := app.GET(asset).Header().Get("Vary"); vary != "Accept-Encoding" {
+		t.Errorf("Vary = %q", vary)
+	}
+
+	etag := app.GET("/logo.png").Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("no ETag")
+	}
+	nomoyutest.AssertStatus(t, app.GET("/logo.png", nomoyutest.WithHeader("If-None-Match", etag)), http.StatusNotModified)
+}
+
+func TestStaticMissingIndexLeavesHeadersClean(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.js":    {Data: []byte("console.log(1)")},
+		"app.js.gz": {Data: []byte("GZ")},
+	}
+	app := nomoyutest.New(t, nomoyutest.WithApp(func(a *nomoyu.App) {
+		a.WithStatic("/", fsys, func(o *nomoyu.StaticOption) { o.SPA = true })
+	}))
+
+	// SPA 回退时 index.html 不存在：404 响应不带缓存与编码相关的响应头
+	rec := app.GET("/users/1", nomoyutest.WithHeader("Accept", "text/html"))
+	nomoyutest.AssertStatus(t, rec, http.StatusNotFound)
+	for _, h := range []string{"Cache-Control", "Vary", "Content-Encoding", "ETag"} {
+		if v := rec.Header().Get(h); v != "" {
+			t.Errorf("%s = %q on 404", h, v)
+		}
+	}
+}
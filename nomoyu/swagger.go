@@ -1,34 +1,43 @@
 package nomoyu
 
 import (
-	"fmt"
 	"github.com/nomoyu/go-gin-framework/internal/swagger"
 	"github.com/nomoyu/go-gin-framework/pkg/config"
 )
 
-func (a *App) WithSwagger() *App {
-	conf := config.Conf.Swagger
-	a.modules = append(a.modules, swagger.New(conf.Route))
+type SwaggerOption struct {
+	Route    string
+	FromUser bool
+}
+
+// WithSwagger 显式开启 Swagger，路由默认取配置 swagger.route
+func (a *App) WithSwagger(route ...string) *App {
+	opt := &SwaggerOption{FromUser: true}
+	if len(route) > 0 {
+		opt.Route = route[0]
+	}
+	a.swaggerOption = opt
 	return a
 }
 
-// initSwaggerFromConfigIfPresent 在 Start() 中自动调用（用户未 WithSwagger）
+// initSwaggerFromConfigIfPresent 在 Build() 中自动调用（WithSwagger 优先，其次配置文件）
 func initSwaggerFromConfigIfPresent(app *App) {
 	if app.hasModule("swagger") {
-		return // 用户已经通过 WithSwagger 显式开启
+		return // 用户已经通过 WithModule 手动挂载
 	}
 
-	conf := config.Conf.Swagger
-	if conf.Enabled {
-		app.modules = append(app.modules, swagger.New(conf.Route))
+	conf := config.Current().Swagger
+	if app.swaggerOption == nil || !app.swaggerOption.FromUser {
+		if !conf.Enabled {
+			return
+		}
+		app.swaggerOption = &SwaggerOption{FromUser: false}
 	}
-}
 
-func (a *App) hasModule(name string) bool {
-	for _, m := range a.modules {
-		if fmt.Sprintf("%T", m) == name {
-			return true
-		}
+	route := app.swaggerOption.Route
+	if route == "" {
+		route = conf.Route // 默认 /swagger/*any
 	}
-	return false
+	app.swaggerOption.Route = route
+	app.modules = append(app.modules, swagger.New(route))
 }
@@ -0,0 +1,100 @@
+package nomoyu
+
+import (
+	"context"
+	"crypto/tls"
+	"time"
+
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+	"github.com/nomoyu/go-gin-framework/pkg/logger"
+	"github.com/nomoyu/go-gin-framework/pkg/tlsx"
+)
+
+type TLSOption struct {
+	CertFile       string
+	KeyFile        string
+	ClientCAFile   string             // 非空时开启 mTLS
+	ClientAuth     tls.ClientAuthType // 未设置（tlsx.ClientAuthDefault）且设置了 ClientCAFile 时默认 RequireAndVerifyClientCert
+	ReloadInterval time.Duration
+	FromUser       bool
+}
+
+// WithTLS 启用 HTTPS（自动协商 HTTP/2），证书文件变化后自动热加载
+func (a *App) WithTLS(certFile, keyFile string, opts ...func(*TLSOption)) *App {
+	opt := TLSOption{CertFile: certFile, KeyFile: keyFile, ClientAuth: tlsx.ClientAuthDefault}
+	for _, f := range opts {
+		f(&opt)
+	}
+	opt.FromUser = true
+	a.tlsOption = &opt
+	return a
+}
+
+// WithH2C 在明文端口上启用 HTTP/2（h2c），适用于网格内 sidecar 终结 TLS 的场景
+func (a *App) WithH2C() *App {
+	a.h2c = true
+	return a
+}
+
+// resolveTLSOption WithTLS 优先，其次配置 server.tls；未启用返回 nil
+func resolveTLSOption(a *App) (*TLSOption, error) {
+	if a.tlsOption != nil && a.tlsOption.FromUser {
+		return a.tlsOption, nil
+	}
+
+	conf := config.Current().Server.TLS
+	if !conf.Enabled {
+		return nil, nil
+	}
+	clientAuth, err := tlsx.ParseClientAuth(conf.ClientAuth)
+	if err != nil {
+		return nil, err
+	}
+	a.tlsOption = &TLSOption{
+		CertFile:       conf.CertFile,
+		KeyFile:        conf.KeyFile,
+		ClientCAFile:   conf.ClientCAFile,
+		ClientAuth:     clientAuth,
+		ReloadInterval: conf.ReloadInterval,
+		FromUser:       false,
+	}
+	return a.tlsOption, nil
+}
+
+// buildTLSConfig 加载证书并启动热加载；未启用 TLS 时返回 nil
+func (a *App) buildTLSConfig() (*tls.Config, error) {
+	opt, err := resolveTLSOption(a)
+	if err != nil || opt == nil {
+		return nil, err
+	}
+
+	reloader, err := tlsx.NewCertReloader(opt.CertFile, opt.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if opt.ClientCAFile != "" {
+		pool, err := tlsx.LoadCertPool(opt.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = opt.ClientAuth
+		if cfg.ClientAuth == tlsx.ClientAuthDefault {
+			cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go reloader.Watch(ctx, opt.ReloadInterval)
+	a.OnShutdown(func(ctx context.Context) error {
+		cancel()
+		return nil
+	})
+	logger.Infof("TLS enabled: cert=%s, mTLS=%v", opt.CertFile, cfg.ClientCAs != nil)
+	return cfg, nil
+}
@@ -0,0 +1,204 @@
+package nomoyu_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nomoyu/go-gin-framework/nomoyu"
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+)
+
+// selfSigned 生成 localhost 的自签名证书，同时可用作服务端证书、客户端证书与 CA
+func selfSigned(t *testing.T, cn string) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: cn},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeCert 把 cn 的自签名证书写入 certFile/keyFile
+func writeCert(t *testing.T, certFile, keyFile, cn string) {
+	t.Helper()
+	certPEM, keyPEM := selfSigned(t, cn)
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeSelfSigned(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, "localhost")
+	return certFile, keyFile
+}
+
+// startTLSApp 启动 HTTPS 应用，端口可连接后返回
+func startTLSApp(t *testing.T, app *nomoyu.App) string {
+	t.Helper()
+	addr := freeAddr(t)
+	runApp(t, app, func() error { return app.Run(addr) }, func() error {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+		}
+		return err
+	})
+	return addr
+}
+
+// tlsGet 信任 caFile 并携带可选的客户端证书请求 /healthz，返回服务端证书
+func tlsGet(addr, caFile string, clientCert *tls.Certificate) (*x509.Certificate, error) {
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(caPEM)
+	cfg := &tls.Config{RootCAs: pool}
+	if clientCert != nil {
+		// 不按服务端的可接受 CA 过滤，总是发送客户端证书
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) { return clientCert, nil }
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}, Timeout: 5 * time.Second}
+	defer client.CloseIdleConnections()
+
+	resp, err := client.Get("https://" + addr + "/healthz")
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return resp.TLS.PeerCertificates[0], nil
+}
+
+func TestTLSClientAuth(t *testing.T) {
+	certFile, keyFile := writeSelfSigned(t)
+	// 不受 CA 信任的客户端证书：只有要求/校验客户端证书时才会被拒绝
+	untrustedPEM, untrustedKey := selfSigned(t, "stranger")
+	untrusted, err := tls.X509KeyPair(untrustedPEM, untrustedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		mode          string
+		ca            bool
+		noCertOK      bool
+		untrustedOK   bool
+		withTLSOption func(*nomoyu.TLSOption)
+	}{
+		{mode: "", ca: true, noCertOK: false, untrustedOK: false},
+		{mode: "none", ca: true, noCertOK: true, untrustedOK: true},
+		{mode: "verify_if_given", ca: true, noCertOK: true, untrustedOK: false},
+		{mode: "", ca: false, noCertOK: true, untrustedOK: true},
+		// WithTLS：未设置时默认校验，显式 NoClientCert 保持不变
+		{mode: "WithTLS default", withTLSOption: func(o *nomoyu.TLSOption) { o.ClientCAFile = certFile }},
+		{mode: "WithTLS none", noCertOK: true, untrustedOK: true, withTLSOption: func(o *nomoyu.TLSOption) {
+			o.ClientCAFile = certFile
+			o.ClientAuth = tls.NoClientCert
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(fmt.Sprintf("%s/ca=%v", tc.mode, tc.ca), func(t *testing.T) {
+			loadTestConfig(t)
+			app := nomoyu.New()
+			if tc.withTLSOption != nil {
+				app.WithTLS(certFile, keyFile, tc.withTLSOption)
+			} else {
+				conf := *config.Current()
+				conf.Server.TLS = config.ServerTLS{Enabled: true, CertFile: certFile, KeyFile: keyFile, ClientAuth: tc.mode}
+				if tc.ca {
+					conf.Server.TLS.ClientCAFile = certFile
+				}
+				config.Set(&conf)
+			}
+			addr := startTLSApp(t, app)
+
+			if _, err := tlsGet(addr, certFile, nil); (err == nil) != tc.noCertOK {
+				t.Errorf("without client cert: err = %v, want ok=%v", err, tc.noCertOK)
+			}
+			if _, err := tlsGet(addr, certFile, &untrusted); (err == nil) != tc.untrustedOK {
+				t.Errorf("with untrusted client cert: err = %v, want ok=%v", err, tc.untrustedOK)
+			}
+		})
+	}
+}
+
+func TestTLSCertificateHotReload(t *testing.T) {
+	loadTestConfig(t)
+	certFile, keyFile := writeSelfSigned(t)
+	addr := startTLSApp(t, nomoyu.New().WithTLS(certFile, keyFile, func(o *nomoyu.TLSOption) {
+		o.ReloadInterval = 20 * time.Millisecond
+	}))
+
+	cert, err := tlsGet(addr, certFile, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.Subject.CommonName != "localhost" {
+		t.Fatalf("served cert CN = %q", cert.Subject.CommonName)
+	}
+
+	// 轮换证书：修改时间推后，避免文件系统时间精度导致检测不到变化
+	writeCert(t, certFile, keyFile, "rotated")
+	later := time.Now().Add(time.Minute)
+	for _, f := range []string{certFile, keyFile} {
+		if err := os.Chtimes(f, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	served := cert.Subject.CommonName
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if cert, err = tlsGet(addr, certFile, nil); err == nil {
+			if served = cert.Subject.CommonName; served == "rotated" {
+				return
+			}
+		}
+	}
+	t.Fatalf("served certificate not reloaded: CN = %q, last error: %v", served, err)
+}
@@ -0,0 +1,244 @@
+package nomoyu
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+	"github.com/nomoyu/go-gin-framework/pkg/logger"
+	"github.com/nomoyu/go-gin-framework/pkg/reqctx"
+)
+
+// VersionStrategy 客户端声明 API 版本的方式
+type VersionStrategy string
+
+const (
+	VersionByPath   VersionStrategy = "path"   // /v1/users
+	VersionByHeader VersionStrategy = "header" // X-API-Version: v1
+	VersionByAccept VersionStrategy = "accept" // Accept: application/json; version=1
+
+	defaultVersionHeader = "X-API-Version"
+)
+
+type VersioningOption struct {
+	Strategy VersionStrategy
+	Header   string // VersionByHeader 使用的请求头，默认 X-API-Version
+	Default  string // 请求未声明版本时使用的版本，为空时取该接口已声明的最新版本
+	FromUser bool
+}
+
+// Deprecation 版本弃用信息，调用时输出 Deprecation / Sunset / Link 响应头并记录告警日志
+type Deprecation struct {
+	At     time.Time // 弃用时间，为空时 Deprecation 头为 true
+	Sunset time.Time // 计划下线时间
+	Link   string    // 迁移说明文档
+}
+
+// WithVersioning 设置 API 版本的声明方式（优先级高于配置 versioning），默认按 URL 前缀
+func (a *App) WithVersioning(strategy VersionStrategy, opts ...func(o *VersioningOption)) *App {
+	opt := &VersioningOption{Strategy: strategy, FromUser: true}
+	for _, fn := range opts {
+		fn(opt)
+	}
+	a.versioningOption = opt
+	return a
+}
+
+// Version 声明分组的 API 版本：版本段插入在该分组前缀之前，如
+// NewGroup("/api").Group("/users", ...) 中对 /users 声明 v1 -> /api/v1/users；子分组继承版本
+func (rg RouteGroup) Version(version string) RouteGroup {
+	rg.version = version
+	return rg
+}
+
+// Deprecated 标记分组（及子分组）已弃用
+func (rg RouteGroup) Deprecated(d Deprecation) RouteGroup {
+	rg.deprecation = &d
+	return rg
+}
+
+// initVersioning 在注册路由分组前确定版本策略；按请求头/Accept 声明版本时创建 versionRouter
+func initVersioning(a *App) error {
+	if a.versioningOption == nil || !a.versioningOption.FromUser {
+		conf := config.Current().Versioning
+		a.versioningOption = &VersioningOption{
+			Strategy: VersionStrategy(conf.Strategy),
+			Header:   conf.Header,
+			Default:  conf.Default,
+		}
+	}
+	opt := a.versioningOption
+	opt.Strategy = firstNonZero(opt.Strategy, VersionByPath)
+	opt.Header = firstNonZero(opt.Header, defaultVersionHeader)
+
+	switch opt.Strategy {
+	case VersionByPath:
+		return nil
+	case VersionByHeader, VersionByAccept:
+		a.versionRouter = newVersionRouter(opt)
+		return nil
+	default:
+		return fmt.Errorf("not support versioning strategy: %s", opt.Strategy)
+	}
+}
+
+// versionMiddleware 记录当前版本到请求上下文，弃用版本输出响应头与告警日志
+func versionMiddleware(version string, d *Deprecation) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqctx.FromGin(c).Version = version
+		if d != nil {
+			if d.At.IsZero() {
+				c.Header("Deprecation", "true")
+			} else {
+				c.Header("Deprecation", "@"+strconv.FormatInt(d.At.Unix(), 10))
+			}
+			if !d.Sunset.IsZero() {
+				c.Header("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+			}
+			if d.Link != "" {
+				c.Writer.Header().Add("Link", fmt.Sprintf(`<%s>; rel="deprecation"`, d.Link))
+			}
+			logger.Warnf("deprecated API called: %s %s (version=%s, client=%s)",
+				c.Request.Method, c.FullPath(), version, c.ClientIP())
+		}
+		c.Next()
+	}
+}
+
+// versionRouter 按请求头/Accept 声明版本时，把不带版本段的请求路径改写为注册时的版本路径
+// （/users -> /v2/users）再交给 gin；是否需要改写由一个只登记了无版本路径的探测 engine 判断
+type versionRouter struct {
+	opt    *VersioningOption
+	probe  *gin.Engine
+	routes map[string]map[string]versionTarget // "METHOD /users/:id" -> 版本(去掉 v) -> 目标
+}
+
+type versionTarget struct {
+	version  string
+	insertAt int // 版本段插入位置（前面的路径段数）
+}
+
+type probeKey struct{}
+
+func newVersionRouter(opt *VersioningOption) *versionRouter {
+	probe := gin.New()
+	probe.RedirectTrailingSlash = false
+	probe.RedirectFixedPath = false
+	return &versionRouter{opt: opt, probe: probe, routes: map[string]map[string]versionTarget{}}
+}
+
+// add 登记版本化路由的无版本形式
+func (vr *versionRouter) add(method, publicPath, version string, insertAt int) {
+	key := routeKey(method, publicPath)
+	targets, ok := vr.routes[key]
+	if !ok {
+		targets = map[string]versionTarget{}
+		vr.routes[key] = targets
+		vr.probe.Handle(method, publicPath, func(c *gin.Context) {
+			*c.Request.Context().Value(probeKey{}).(*string) = key
+		})
+	}
+	targets[normalizeVersion(version)] = versionTarget{version: version, insertAt: insertAt}
+}
+
+func (vr *versionRouter) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var key string
+		vr.probe.ServeHTTP(discardWriter{}, r.WithContext(context.WithValue(r.Context(), probeKey{}, &key)))
+		if key != "" {
+			if t, ok := vr.resolve(vr.routes[key], vr.requested(r)); ok {
+				r.URL.Path = insertSegment(r.URL.Path, t.insertAt, t.version)
+				r.URL.RawPath = ""
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requested 从请求头或 Accept 的 version 参数中取客户端声明的版本
+func (vr *versionRouter) requested(r *http.Request) string {
+	if vr.opt.Strategy == VersionByHeader {
+		return r.Header.Get(vr.opt.Header)
+	}
+	for _, accept := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(accept, ",") {
+			if _, params, err := mime.ParseMediaType(strings.TrimSpace(part)); err == nil && params["version"] != "" {
+				return params["version"]
+			}
+		}
+	}
+	return ""
+}
+
+// resolve 客户端声明的版本 > 默认版本 > 该接口已声明的最新版本；声明了不存在的版本时不改写（返回 404）
+func (vr *versionRouter) resolve(targets map[string]versionTarget, requested string) (versionTarget, bool) {
+	if requested == "" {
+		requested = vr.opt.Default
+	}
+	if requested != "" {
+		t, ok := targets[normalizeVersion(requested)]
+		return t, ok
+	}
+	var latest versionTarget
+	for _, t := range targets {
+		if latest.version == "" || versionLess(latest.version, t.version) {
+			latest = t
+		}
+	}
+	return latest, latest.version != ""
+}
+
+func normalizeVersion(v string) string {
+	return strings.TrimLeft(strings.ToLower(strings.TrimSpace(v)), "v")
+}
+
+// versionLess 按数字段比较版本号：v2 < v10，1.2 < 1.10
+func versionLess(a, b string) bool {
+	as := strings.Split(normalizeVersion(a), ".")
+	bs := strings.Split(normalizeVersion(b), ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		ai, aerr := strconv.Atoi(as[i])
+		bi, berr := strconv.Atoi(bs[i])
+		if aerr != nil || berr != nil {
+			if as[i] != bs[i] {
+				return as[i] < bs[i]
+			}
+			continue
+		}
+		if ai != bi {
+			return ai < bi
+		}
+	}
+	return len(as) < len(bs)
+}
+
+// insertSegment 在第 n 个路径段之后插入 seg：("/api/users/1", 1, "v2") -> "/api/v2/users/1"
+func insertSegment(path string, n int, seg string) string {
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if n > len(parts) {
+		n = len(parts)
+	}
+	parts = append(parts[:n], append([]string{seg}, parts[n:]...)...)
+	return "/" + strings.Join(parts, "/")
+}
+
+// pathSegments 路径段数，"/" 为 0
+func pathSegments(path string) int {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return 0
+	}
+	return strings.Count(path, "/") + 1
+}
+
+type discardWriter struct{}
+
+func (discardWriter) Header() http.Header         { return http.Header{} }
+func (discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (discardWriter) WriteHeader(int)             {}
@@ -0,0 +1,86 @@
+package nomoyu_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/nomoyu"
+	"github.com/nomoyu/go-gin-framework/nomoyu/nomoyutest"
+	"github.com/nomoyu/go-gin-framework/pkg/reqctx"
+)
+
+// versionEcho 响应 "<tag> <版本> <路由>"
+func versionEcho(tag string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.String(http.StatusOK, tag+" "+reqctx.FromGin(c).Version+" "+c.FullPath())
+	}
+}
+
+func newVersionedApp(t *testing.T, strategy nomoyu.VersionStrategy) *nomoyutest.App {
+	return nomoyutest.New(t, nomoyutest.WithApp(func(a *nomoyu.App) {
+		a.WithVersioning(strategy).WithRoute(
+			nomoyu.NewGroup("/api").
+				Group("/users", func(g nomoyu.RouteGroup) nomoyu.RouteGroup {
+					return g.Version("v1").Deprecated(nomoyu.Deprecation{Sunset: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), Link: "https://x/migrate"}).
+						GET("/:id", versionEcho("one"))
+				}).
+				Group("/users", func(g nomoyu.RouteGroup) nomoyu.RouteGroup {
+					return g.Version("v2").GET("/:id", versionEcho("two"))
+				}),
+			nomoyu.NewGroup("/plain").GET("", versionEcho("plain")),
+		)
+	}))
+}
+
+func assertBody(t *testing.T, rec *httptest.ResponseRecorder, body string) {
+	t.Helper()
+	if rec.Code != http.StatusOK || rec.Body.String() != body {
+		t.Fatalf("got %d %q, want %q", rec.Code, rec.Body.String(), body)
+	}
+}
+
+func TestVersionByPath(t *testing.T) {
+	app := newVersionedApp(t, nomoyu.VersionByPath)
+	rec := app.GET("/api/v1/users/3")
+	assertBody(t, rec, "one v1 /api/v1/users/:id")
+	if rec.Header().Get("Deprecation") != "true" || rec.Header().Get("Sunset") != "Fri, 01 Jan 2027 00:00:00 GMT" ||
+		rec.Header().Get("Link") != `<https://x/migrate>; rel="deprecation"` {
+		t.Fatalf("deprecation headers: %v", rec.Header())
+	}
+	assertBody(t, app.GET("/api/v2/users/3"), "two v2 /api/v2/users/:id")
+	if rec := app.GET("/api/v2/users/3"); rec.Header().Get("Deprecation") != "" {
+		t.Fatal("v2 marked deprecated")
+	}
+	nomoyutest.AssertStatus(t, app.GET("/api/users/3"), http.StatusNotFound)
+}
+
+func TestVersionByHeader(t *testing.T) {
+	app := newVersionedApp(t, nomoyu.VersionByHeader)
+	assertBody(t, app.GET("/api/users/3"), "two v2 /api/v2/users/:id")
+	assertBody(t, app.GET("/api/users/3", nomoyutest.WithHeader("X-API-Version", "1")), "one v1 /api/v1/users/:id")
+	nomoyutest.AssertStatus(t, app.GET("/api/users/3", nomoyutest.WithHeader("X-API-Version", "v3")), http.StatusNotFound)
+	// 带版本的路径仍然可用，未分版本的分组不受影响
+	assertBody(t, app.GET("/api/v1/users/3"), "one v1 /api/v1/users/:id")
+	assertBody(t, app.GET("/plain", nomoyutest.WithHeader("X-API-Version", "v1")), "plain  /plain")
+}
+
+func TestVersionByAccept(t *testing.T) {
+	app := newVersionedApp(t, nomoyu.VersionByAccept)
+	assertBody(t, app.GET("/api/users/3", nomoyutest.WithHeader("Accept", "text/html, application/json; version=1")), "one v1 /api/v1/users/:id")
+
+	versions := map[string]bool{}
+	for _, r := range app.Routes() {
+		if r.Version != "" {
+			versions[r.Path] = r.Deprecated
+		}
+	}
+	if deprecated, ok := versions["/api/v1/users/:id"]; !ok || !deprecated {
+		t.Errorf("v1 route: %v", versions)
+	}
+	if deprecated, ok := versions["/api/v2/users/:id"]; !ok || deprecated {
+		t.Errorf("v2 route: %v", versions)
+	}
+}
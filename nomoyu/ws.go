@@ -0,0 +1,100 @@
+package nomoyu
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+	"github.com/nomoyu/go-gin-framework/pkg/logger"
+	"github.com/nomoyu/go-gin-framework/pkg/ws"
+)
+
+const protocolWebSocket = "websocket"
+
+// WebSocketOption WebSocket 连接参数，零值字段回退到配置 websocket.* 与 pkg/ws 默认值
+type WebSocketOption struct {
+	PingInterval   time.Duration
+	WriteWait      time.Duration
+	MaxMessageSize int64
+	SendBuffer     int
+	AllowedOrigins []string // 为空时只允许同源，"*" 允许任意来源
+	FromUser       bool
+}
+
+// WithWebSocket 手动指定 WebSocket 参数（优先级高于配置文件）
+func (a *App) WithWebSocket(opts ...func(o *WebSocketOption)) *App {
+	opt := &WebSocketOption{FromUser: true}
+	for _, fn := range opts {
+		fn(opt)
+	}
+	a.wsOption = opt
+	return a
+}
+
+// WS 注册 WebSocket 路由：GET 握手通过分组的认证后升级连接并交给 handler，
+// 连接加入应用的 Hub（见 App.WSHub），停机时统一发送关闭帧
+//
+// 认证 token 可放在 Authorization 头、子协议 ["bearer", token] 或 query access_token/token 中
+func (rg RouteGroup) WS(path string, handler ws.Handler, opts ...RouteOption) RouteGroup {
+	return rg.addRoute(routeSpec{methods: []string{http.MethodGet}, path: path, ws: handler}, opts)
+}
+
+// WSHub 返回应用的 WebSocket Hub（用于在业务代码中广播），需在配置加载后调用
+func (a *App) WSHub() *ws.Hub {
+	if a.wsHub == nil {
+		a.wsHub = ws.NewHub(resolveWebSocketOption(a))
+	}
+	return a.wsHub
+}
+
+// resolveWebSocketOption 合并 WithWebSocket > 配置 websocket.*
+func resolveWebSocketOption(a *App) ws.Options {
+	opt := WebSocketOption{}
+	if a.wsOption != nil {
+		opt = *a.wsOption
+	}
+	if config.Current() != nil {
+		conf := config.Current().WebSocket
+		opt.PingInterval = firstNonZero(opt.PingInterval, conf.PingInterval)
+		opt.WriteWait = firstNonZero(opt.WriteWait, conf.WriteWait)
+		opt.MaxMessageSize = firstNonZero(opt.MaxMessageSize, conf.MaxMessageSize)
+		opt.SendBuffer = firstNonZero(opt.SendBuffer, conf.SendBuffer)
+		opt.AllowedOrigins = firstOr(opt.AllowedOrigins, conf.AllowedOrigins)
+	}
+	return ws.Options{
+		PingInterval:   opt.PingInterval,
+		WriteWait:      opt.WriteWait,
+		MaxMessageSize: opt.MaxMessageSize,
+		SendBuffer:     opt.SendBuffer,
+		AllowedOrigins: opt.AllowedOrigins,
+	}
+}
+
+// wsHandler 握手通过后升级连接，认证信息（AuthInfo）作为 Conn.User
+func (a *App) wsHandler(handler ws.Handler) gin.HandlerFunc {
+	hub := a.WSHub()
+	return func(c *gin.Context) {
+		var user map[string]interface{}
+		if v, ok := c.Get("AuthInfo"); ok {
+			user, _ = v.(map[string]interface{})
+		}
+		if err := hub.Serve(c.Writer, c.Request, user, handler); err != nil {
+			logger.Warnf("websocket upgrade failed: %s %v", c.Request.URL.Path, err)
+		}
+	}
+}
+
+// wsTokenMiddleware 把子协议/query 中的 token 转成 Authorization 头，认证策略无需感知 WebSocket
+func wsTokenMiddleware(c *gin.Context) {
+	copyWSToken(c)
+	c.Next()
+}
+
+func copyWSToken(c *gin.Context) {
+	if c.GetHeader("Authorization") == "" {
+		if token := ws.TokenFromRequest(c.Request); token != "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+}
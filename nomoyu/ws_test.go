@@ -0,0 +1,131 @@
+package nomoyu_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/nomoyu/go-gin-framework/nomoyu"
+	"github.com/nomoyu/go-gin-framework/nomoyu/nomoyutest"
+	"github.com/nomoyu/go-gin-framework/pkg/ws"
+)
+
+func TestWebSocket(t *testing.T) {
+	app := nomoyutest.New(t, nomoyutest.WithConfig("auth:\n  enabled: true\n  mode: jwt\n  jwt:\n    secret: s\nwebsocket:\n  send_buffer: 4\n"),
+		nomoyutest.WithApp(func(a *nomoyu.App) {
+			a.WithRoute(nomoyu.NewGroup("/ws").RequireAuth().
+				WS("/chat", func(c *ws.Conn) {
+					c.Join("lobby")
+					c.SendJSON(map[string]any{"hello": c.User["name"]})
+					for {
+						_, msg, err := c.Read()
+						if err != nil {
+							return
+						}
+						c.Hub().BroadcastRoom("lobby", msg)
+					}
+				}).
+				WS("/push", func(c *ws.Conn) { c.Wait() }))
+		}))
+	srv := httptest.NewServer(app.Handler())
+	defer srv.Close()
+	base := "ws" + strings.TrimPrefix(srv.URL, "http")
+	token := app.MintJWT("1", "tom")
+	read := func(c *websocket.Conn) string {
+		t.Helper()
+		c.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, msg, err := c.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(msg)
+	}
+
+	if _, resp, err := websocket.DefaultDialer.Dial(base+"/ws/chat", nil); err == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("dial without token: %v", err)
+	}
+	if _, resp, err := websocket.DefaultDialer.Dial(base+"/ws/chat?token="+token, http.Header{"Origin": {"http://evil.com"}}); err == nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("cross-origin dial: %v", err)
+	}
+
+	// 令牌可以放在查询参数或子协议中
+	c1, _, err := websocket.DefaultDialer.Dial(base+"/ws/chat?access_token="+token, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c1.Close()
+	if msg := read(c1); msg != `{"hello":"tom"}` {
+		t.Fatalf("hello: %s", msg)
+	}
+	d := websocket.Dialer{Subprotocols: []string{"bearer", token}}
+	c2, _, err := d.Dial(base+"/ws/chat", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c2.Close()
+	if c2.Subprotocol() != "bearer" {
+		t.Fatalf("subprotocol %q", c2.Subprotocol())
+	}
+	read(c2)
+
+	c1.WriteMessage(websocket.TextMessage, []byte("hi all"))
+	if read(c2) != "hi all" || read(c1) != "hi all" {
+		t.Fatal("room broadcast not delivered")
+	}
+	hub := app.WSHub()
+	if hub.Count() != 2 || hub.RoomCount("lobby") != 2 {
+		t.Fatalf("count=%d room=%d", hub.Count(), hub.RoomCount("lobby"))
+	}
+
+	// 不读取消息的连接在发送队列满后被断开
+	c3, _, err := websocket.DefaultDialer.Dial(base+"/ws/push?token="+token, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c3.Close()
+	for deadline := time.Now().Add(time.Second); hub.Count() < 3 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	big := make([]byte, 1<<20)
+	var sendErr error
+	for i := 0; i < 200 && sendErr == nil; i++ {
+		hub.Each(func(c *ws.Conn) {
+			if strings.HasSuffix(c.Request.URL.Path, "/push") {
+				sendErr = c.Send(big)
+			}
+		})
+	}
+	if !errors.Is(sendErr, ws.ErrSlowConsumer) {
+		t.Fatalf("slow consumer: %v", sendErr)
+	}
+	// 客户端不关闭时服务端的写操作要等到 write_wait 超时
+	c3.Close()
+
+	// 停机时发送关闭帧
+	done := make(chan error)
+	go func() { done <- hub.Close(t.Context()) }()
+	c1.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		_, _, err := c1.ReadMessage()
+		if err == nil {
+			continue
+		}
+		if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+			t.Fatalf("close: %v", err)
+		}
+		break
+	}
+	if err := <-done; err != nil || hub.Count() != 0 {
+		t.Fatalf("hub close: %v, %d left", err, hub.Count())
+	}
+
+	for _, r := range app.Routes() {
+		if strings.HasPrefix(r.Path, "/ws/") && (r.Protocol != "websocket" || !r.RequireAuth) {
+			t.Errorf("route %s: %+v", r.Path, r)
+		}
+	}
+}
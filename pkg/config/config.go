@@ -4,20 +4,30 @@ import (
 	"log"
 	"os"
 	"time"
-
-	"github.com/spf13/viper"
 )
 
+// AppConfig 框架配置：default 标签为未配置时的默认值，validate 标签为启动时的校验规则（见 Validate），
+// secret:"true" 的字段在展示、日志时脱敏（见 Masked）
 type AppConfig struct {
-	App      App           `mapstructure:"app"`
-	Server   Server        `mapstructure:"server"`
-	Database Database      `mapstructure:"database"`
-	Log      Log           `mapstructure:"log"`
-	Swagger  SwaggerConfig `mapstructure:"swagger"`
-	Auth     AuthConfig    `mapstructure:"auth"`
-	Config   ConfigCenter  `mapstructure:"config"`
-	Redis    RedisConfig   `mapstructure:"redis"`
-	CORS     CORS          `mapstructure:"cors"`
+	App        App           `mapstructure:"app"`
+	Server     Server        `mapstructure:"server"`
+	Database   Database      `mapstructure:"database"`
+	Log        Log           `mapstructure:"log"`
+	Swagger    SwaggerConfig `mapstructure:"swagger"`
+	OpenAPI    OpenAPIConfig `mapstructure:"openapi"`
+	Versioning Versioning    `mapstructure:"versioning"`
+	Auth       AuthConfig    `mapstructure:"auth"`
+	Config     ConfigCenter  `mapstructure:"config"`
+	Redis      RedisConfig   `mapstructure:"redis"`
+	CORS       CORS          `mapstructure:"cors"`
+	Admin      Admin         `mapstructure:"admin"`
+	WebSocket  WebSocket     `mapstructure:"websocket"`
+	SSE        SSE           `mapstructure:"sse"`
+	RateLimit  RateLimit     `mapstructure:"rate_limit"`
+	Scheduler  Scheduler     `mapstructure:"scheduler"`
+
+	// sections Register 注册的配置段，随快照一起替换
+	sections map[string]any
 }
 
 type App struct {
@@ -27,92 +37,222 @@ type App struct {
 }
 
 type Server struct {
-	Host string `mapstructure:"host"`
-	Port int    `mapstructure:"port"`
+	Host string    `mapstructure:"host"`
+	Port int       `mapstructure:"port" default:"3303" validate:"min=0,max=65535"`
+	TLS  ServerTLS `mapstructure:"tls"`
+	H2C  bool      `mapstructure:"h2c"` // 明文 HTTP/2（服务网格 sidecar 场景），启用 TLS 时忽略
+	// 平滑重启：收到 SIGHUP/SIGUSR2 时把监听 socket 交给新进程，新进程就绪后旧进程优雅退出
+	GracefulRestart bool `mapstructure:"graceful_restart"`
+	// 以下为 http.Server 参数（字符串形式，如 30s/2m）
+	ReadTimeout       time.Duration `mapstructure:"read_timeout" default:"10s" validate:"min=0"`
+	WriteTimeout      time.Duration `mapstructure:"write_timeout" default:"30s" validate:"min=0"`
+	IdleTimeout       time.Duration `mapstructure:"idle_timeout" default:"60s" validate:"min=0"`
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout" validate:"min=0"`
+	MaxHeaderBytes    int           `mapstructure:"max_header_bytes" validate:"min=0"`
+	ShutdownTimeout   time.Duration `mapstructure:"shutdown_timeout" default:"10s" validate:"min=0"`
+	// Unix domain socket：配置后不再监听 host:port
+	UnixSocket string `mapstructure:"unix_socket"`                               // 如 /run/app/app.sock
+	SocketMode string `mapstructure:"socket_mode" validate:"omitempty,filemode"` // 八进制权限，如 "0660"
+}
+
+type ServerTLS struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	CertFile     string `mapstructure:"cert_file"`
+	KeyFile      string `mapstructure:"key_file"`
+	ClientCAFile string `mapstructure:"client_ca_file"` // 配置后默认要求并校验客户端证书（mTLS）
+	// 客户端证书策略：none / request / require / verify_if_given / require_and_verify
+	ClientAuth     string        `mapstructure:"client_auth" validate:"omitempty,oneof=none request require verify_if_given require_and_verify"`
+	ReloadInterval time.Duration `mapstructure:"reload_interval" default:"10s" validate:"min=0"` // 证书文件变化检查间隔
 }
 
 type Database struct {
-	Dialect     string `mapstructure:"dialect"`
+	Dialect     string `mapstructure:"dialect" validate:"omitempty,oneof=mysql postgres sqlite"`
 	Host        string `mapstructure:"host"`
-	Port        int    `mapstructure:"port"`
+	Port        int    `mapstructure:"port" validate:"omitempty,min=1,max=65535"`
 	User        string `mapstructure:"user"`
-	Password    string `mapstructure:"password"`
+	Password    string `mapstructure:"password" secret:"true"`
 	DBName      string `mapstructure:"dbname"`
 	AutoMigrate bool   `mapstructure:"autoMigrate"`
 }
 
 type RedisConfig struct {
 	// 模式：空或 "single" 使用单点；"cluster" 使用集群（需填写 Addrs）
-	Mode     string   `mapstructure:"mode"`
-	Addr     string   `mapstructure:"addr"`  // 单点：host:port
-	Addrs    []string `mapstructure:"addrs"` // 集群：节点列表
-	Password string   `mapstructure:"password"`
-	DB       int      `mapstructure:"db"`
-	PoolSize int      `mapstructure:"pool_size"`
+	Mode     string   `mapstructure:"mode" validate:"omitempty,oneof=single cluster"`
+	Addr     string   `mapstructure:"addr" validate:"omitempty,hostname_port"` // 单点：host:port
+	Addrs    []string `mapstructure:"addrs" validate:"dive,hostname_port"`     // 集群：节点列表
+	Password string   `mapstructure:"password" secret:"true"`
+	DB       int      `mapstructure:"db" validate:"min=0"`
+	PoolSize int      `mapstructure:"pool_size" default:"20" validate:"min=0"`
 	// 可选超时（字符串形式，viper 能解析 500ms/2s/1m 等）
-	DialTimeout  time.Duration `mapstructure:"dial_timeout"`
-	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
-	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	DialTimeout  time.Duration `mapstructure:"dial_timeout" default:"2s" validate:"min=0"`
+	ReadTimeout  time.Duration `mapstructure:"read_timeout" default:"1s" validate:"min=0"`
+	WriteTimeout time.Duration `mapstructure:"write_timeout" default:"1s" validate:"min=0"`
 }
 
 type Log struct {
-	Level string `mapstructure:"level"`
-	Path  string `mapstructure:"path"`
+	Level string `mapstructure:"level" default:"info" validate:"oneof=debug info warn error dpanic panic fatal"`
+	Path  string `mapstructure:"path" default:"./logs" validate:"required"`
 }
 
 type SwaggerConfig struct {
 	Enabled bool   `mapstructure:"enabled"`
-	Route   string `mapstructure:"route"`
+	Route   string `mapstructure:"route" default:"/swagger/*any" validate:"startswith=/"`
+}
+
+type Versioning struct {
+	Strategy string `mapstructure:"strategy" default:"path" validate:"oneof=path header accept"`
+	Header   string `mapstructure:"header" default:"X-API-Version" validate:"required"` // strategy=header 时的请求头
+	Default  string `mapstructure:"default"`                                            // 未声明版本时使用的版本，默认取最新版本
+}
+
+type OpenAPIConfig struct {
+	Enabled     bool     `mapstructure:"enabled"`
+	Title       string   `mapstructure:"title"` // 默认 app.name
+	Description string   `mapstructure:"description"`
+	Servers     []string `mapstructure:"servers" validate:"dive,url"` // 文档挂在管理端口时，Try it out 需要指向业务地址
 }
 
 type AuthConfig struct {
 	Enabled bool   `mapstructure:"enabled"`
-	Mode    string `mapstructure:"mode"`
+	Mode    string `mapstructure:"mode" default:"jwt" validate:"oneof=jwt"`
 	JWT     struct {
-		Secret string `mapstructure:"secret"`
+		Secret string `mapstructure:"secret" secret:"true"`
 	} `mapstructure:"jwt"`
 }
 
+// RemoteConfig 远程配置客户端：从另一个开启了配置中心的 nomoyu 实例拉取本应用（app.name/app.env）的文档，
+// 叠加在本地配置文件之上，变化时热更新
 type RemoteConfig struct {
-	Addr string `mapstructure:"addr"`
+	Addr     string        `mapstructure:"addr" validate:"omitempty,url"`                     // 配置中心地址，如 http://10.0.0.2:9090
+	Token    string        `mapstructure:"token" secret:"true" validate:"required_with=Addr"` // 与配置中心的 config.center.token 一致
+	Interval time.Duration `mapstructure:"interval" default:"30s" validate:"min=0"`           // 出错后的重试间隔；关闭长轮询时为轮询间隔
+	LongPoll time.Duration `mapstructure:"long_poll" default:"25s" validate:"min=0"`          // 长轮询等待时间，0 表示普通轮询
+	CacheDir string        `mapstructure:"cache_dir" default:"./config-cache"`                // 最近一次拉取成功的文档，配置中心不可用时启动使用
 }
 
 type CORS struct {
 	Enabled          bool     `mapstructure:"enabled"`
 	AllowOrigins     []string `mapstructure:"allow_origins"`
-	AllowMethods     []string `mapstructure:"allow_methods"`
-	AllowHeaders     []string `mapstructure:"allow_headers"`
+	AllowMethods     []string `mapstructure:"allow_methods" default:"GET,POST,PUT,DELETE,OPTIONS"`
+	AllowHeaders     []string `mapstructure:"allow_headers" default:"Origin,Content-Type,Accept,Authorization"`
 	ExposeHeaders    []string `mapstructure:"expose_headers"`
 	AllowCredentials bool     `mapstructure:"allow_credentials"`
-	MaxAge           int      `mapstructure:"max_age"` // 秒
+	MaxAge           int      `mapstructure:"max_age" validate:"min=0"` // 秒
+}
+
+// Admin 管理端口：配置 addr 后 swagger、配置中心、pprof、日志级别等运维接口只在该端口提供
+type Admin struct {
+	Addr string `mapstructure:"addr" validate:"omitempty,hostname_port"` // 如 127.0.0.1:9090
+	// 允许访问管理端口的 IP 或网段（按直连地址判断），为空时只允许本机
+	AllowIPs []string `mapstructure:"allow_ips" validate:"dive,cidr|ip"`
+	// 允许监听非回环地址（如 0.0.0.0:9090），否则拒绝启动
+	Public bool `mapstructure:"public"`
+}
+
+// WebSocket 连接参数
+type WebSocket struct {
+	PingInterval   time.Duration `mapstructure:"ping_interval" default:"30s" validate:"min=0"`
+	WriteWait      time.Duration `mapstructure:"write_wait" default:"10s" validate:"min=0"`
+	MaxMessageSize int64         `mapstructure:"max_message_size" default:"65536" validate:"min=0"` // 字节
+	SendBuffer     int           `mapstructure:"send_buffer" default:"256" validate:"min=0"`        // 每个连接的发送队列长度
+	AllowedOrigins []string      `mapstructure:"allowed_origins"`                                   // 为空时只允许同源
+}
+
+// SSE 事件流参数
+type SSE struct {
+	Heartbeat    time.Duration `mapstructure:"heartbeat" default:"15s" validate:"min=0"`
+	ReplaySize   int           `mapstructure:"replay_size" default:"100" validate:"min=0"` // 每个主题保留的补发事件数
+	ReplayTTL    time.Duration `mapstructure:"replay_ttl" default:"5m" validate:"min=0"`
+	ClientBuffer int           `mapstructure:"client_buffer" default:"64" validate:"min=0"`
+	Retry        time.Duration `mapstructure:"retry" validate:"min=0"` // 建议客户端重连间隔
+	// Redis 为 true 时通过 redis pub/sub 在多实例间广播（需配置 redis）
+	Redis        bool   `mapstructure:"redis"`
+	RedisChannel string `mapstructure:"redis_channel" default:"nomoyu:sse"`
+}
+
+// RateLimit 按客户端地址限流（令牌桶），支持热更新
+type RateLimit struct {
+	Enabled bool    `mapstructure:"enabled"`
+	Rate    float64 `mapstructure:"rate" default:"100" validate:"gt=0"`   // 每个客户端每秒补充的令牌数
+	Burst   int     `mapstructure:"burst" default:"200" validate:"min=1"` // 令牌桶容量，即允许的突发请求数
+}
+
+// Scheduler 定时任务调度，支持热更新
+type Scheduler struct {
+	Disabled []string `mapstructure:"disabled"` // 暂停执行的任务名，移出列表后恢复
 }
 
 type ConfigCenter struct {
+	Watch  bool         `mapstructure:"watch"` // 监听配置文件变化并热更新（日志级别、CORS 等）
+	Center CenterConfig `mapstructure:"center"`
 	Remote RemoteConfig `mapstructure:"remote"`
 }
 
+// CenterConfig 内置配置中心：在线编辑叠加在配置文件之上的文档，保存版本历史与审计记录
+type CenterConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Dir     string `mapstructure:"dir" default:"./config-center" validate:"required"` // 版本与审计记录的存储目录
+	// 远程配置客户端拉取文档（含密钥原文）使用的令牌，未配置时不对外提供文档
+	Token string `mapstructure:"token" secret:"true"`
+	// 只读：页面与接口可查看、对比，不能保存或回滚
+	ReadOnly bool `mapstructure:"read_only"`
+	// 允许访问页面与接口的 IP 或网段（按直连地址判断），为空不限制
+	AllowIPs []string          `mapstructure:"allow_ips" validate:"dive,cidr|ip"`
+	Admin    CenterAdminConfig `mapstructure:"admin"`
+}
+
+// CenterAdminConfig 配置中心的访问凭据：配置了专用账号时使用 HTTP Basic 认证，
+// 否则使用应用的认证策略并要求 Role 角色
+type CenterAdminConfig struct {
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password" secret:"true" validate:"required_with=Username"`
+	Role     string `mapstructure:"role" default:"admin"`
+}
+
+// Conf 启动时 Load 的配置（兼容旧用法），只在 Load/LoadBytes/Set 时赋值：不包含之后的热更新、配置中心与远程配置，
+// 运行中读取配置请使用 Current()
 var Conf *AppConfig
 
+// InitConfig 读取配置文件，失败直接退出（兼容旧用法）
 func InitConfig() {
-	env := os.Getenv("GO_ENV")
-	if env == "" {
-		env = "dev"
+	if err := Load(); err != nil {
+		log.Fatalf("%v", err)
 	}
+	log.Println("config success init...")
+}
 
-	configFile := "config." + env + ".yaml"
-	viper.SetConfigFile(configFile)
-	viper.SetConfigType("yaml")
-
-	err := viper.ReadInConfig()
+// Load 读取配置并写入 Conf 与 Current()，失败时返回错误而不是退出进程；Load/LoadBytes 会清空之前设置的配置层（见 SetLayer）
+//
+// 查找目录（默认 . 与 ./configs）中依次合并 config.yaml、config.<env>.yaml、config.local.yaml，
+// 再叠加 NOMOYU_ 前缀的环境变量；命令行 --config 指定单个文件，--env 指定环境
+func Load() error {
+	src, err := resolveSources(os.Args[1:])
+	if err != nil {
+		return err
+	}
+	config, settings, err := src.read()
 	if err != nil {
-		log.Fatalf("failed to read config file: %v", err)
+		return err
 	}
 
-	var config AppConfig
-	if err := viper.Unmarshal(&config); err != nil {
-		log.Fatalf("failed to parse config file: %v", err)
+	sources = src
+	resetLayers()
+	Conf = config
+	store(config, settings)
+	return nil
+}
+
+// LoadBytes 从内存中的 YAML 加载配置并写入 Conf 与 Current()（测试、内嵌配置等场景）
+func LoadBytes(data []byte) error {
+	src := &sourceSet{raw: append([]byte{}, data...)}
+	config, settings, err := src.readWith(nil)
+	if err != nil {
+		return err
 	}
 
-	Conf = &config
-	log.Println("config success init...")
+	sources = src
+	resetLayers()
+	Conf = config
+	store(config, settings)
+	return nil
 }
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// layer 叠加在配置文件之上、环境变量之下的一层 YAML（配置中心、远程配置等）
+type layer struct {
+	name string
+	data []byte
+}
+
+var (
+	layersMu sync.Mutex
+	layers   []layer
+)
+
+// SetLayer 设置名为 name 的配置层（多个配置层按首次设置的顺序合并），data 为空表示移除；
+// 合并后的配置校验通过才会原子替换当前配置并通知订阅者，否则配置层与当前配置都保持不变
+func SetLayer(name string, data []byte) error {
+	src := sources
+	if src == nil {
+		return fmt.Errorf("config: cannot set layer %s: %w", name, errNotLoaded)
+	}
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	ls := withLayer(currentLayers(), name, data)
+	c, settings, err := src.readWith(ls)
+	if err != nil {
+		return fmt.Errorf("config: layer %s rejected: %w", name, err)
+	}
+	layersMu.Lock()
+	layers = ls
+	layersMu.Unlock()
+	replace(c, settings)
+	return nil
+}
+
+// CheckLayer 校验把 data 作为配置层 name 后的配置，不应用；返回合并后的配置
+func CheckLayer(name string, data []byte) (*AppConfig, error) {
+	src := sources
+	if src == nil {
+		return nil, fmt.Errorf("config: cannot check layer %s: %w", name, errNotLoaded)
+	}
+	c, _, err := src.readWith(withLayer(currentLayers(), name, data))
+	return c, err
+}
+
+// CheckDocument 校验其他应用（app/env 与本应用不同）的配置文档：与 Load 使用相同的解码与校验规则（含注册的配置段），
+// 但该应用的配置文件与环境变量不在本机，因此文档叠加在默认值上解码，只报告文档中出现的键的问题
+// （文档以外的必填项等由该应用拉取时校验），${env:}/${file:}/enc: 也不在本机解析，只检查是否用在 secret 字段上
+func CheckDocument(data []byte) error {
+	if err := checkLayerRefs(layer{name: "document", data: data}); err != nil {
+		return err
+	}
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to read config document: %w", err)
+	}
+	keys := v.AllKeys()
+	applyDefaults(v)
+
+	var c AppConfig
+	if err := v.Unmarshal(&c); err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	sections, err := decodeSections(v.AllSettings())
+	if err != nil {
+		return err
+	}
+	c.sections = sections
+
+	err = Validate(&c)
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		return err
+	}
+	out := &ValidationError{}
+	for _, is := range ve.Issues {
+		if inDocument(keys, is.Key) {
+			out.Issues = append(out.Issues, is)
+		}
+	}
+	if len(out.Issues) > 0 {
+		return out
+	}
+	return nil
+}
+
+// inDocument 问题的键（如 redis.addrs[0]、配置段名 payment）或其下级键是否出现在文档中
+func inDocument(keys []string, key string) bool {
+	key, _, _ = strings.Cut(strings.ToLower(key), "[")
+	for _, k := range keys {
+		if k == key || strings.HasPrefix(k, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Layer 返回配置层 name 当前的内容
+func Layer(name string) []byte {
+	for _, l := range currentLayers() {
+		if l.name == name {
+			return append([]byte(nil), l.data...)
+		}
+	}
+	return nil
+}
+
+func currentLayers() []layer {
+	layersMu.Lock()
+	defer layersMu.Unlock()
+	return append([]layer(nil), layers...)
+}
+
+func resetLayers() {
+	layersMu.Lock()
+	layers = nil
+	layersMu.Unlock()
+}
+
+// withLayer 返回替换（或追加、移除）name 后的新列表，不修改 ls
+func withLayer(ls []layer, name string, data []byte) []layer {
+	out := make([]layer, 0, len(ls)+1)
+	found := false
+	for _, l := range ls {
+		if l.name == name {
+			found = true
+			if len(bytes.TrimSpace(data)) == 0 {
+				continue
+			}
+			l.data = data
+		}
+		out = append(out, l)
+	}
+	if !found && len(bytes.TrimSpace(data)) > 0 {
+		out = append(out, layer{name: name, data: data})
+	}
+	return out
+}
@@ -0,0 +1,239 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix 环境变量覆盖的前缀：database.password -> NOMOYU_DATABASE_PASSWORD
+const EnvPrefix = "NOMOYU"
+
+// searchPaths 配置文件的查找目录，按顺序取第一个存在的文件
+var searchPaths = []string{".", "./configs"}
+
+// SetSearchPaths 设置配置文件的查找目录（默认 "." 与 "./configs"），需在 Load 之前调用
+func SetSearchPaths(paths ...string) {
+	searchPaths = append([]string(nil), paths...)
+}
+
+// BindFlags 把 --config/--env 注册到 fs（通常为 flag.CommandLine），避免应用自己 flag.Parse 时报未定义；
+// 取值由 Load 直接从命令行参数中解析
+func BindFlags(fs *flag.FlagSet) {
+	fs.String("config", "", "config file, skips layered config.yaml/config.<env>.yaml/config.local.yaml lookup")
+	fs.String("env", "", "config environment, overrides NOMOYU_ENV and GO_ENV")
+}
+
+// sourceSet 一次加载使用的配置来源，热更新时按同样的规则重新读取
+type sourceSet struct {
+	env   string
+	files []string // 按合并顺序排列，后面的覆盖前面的
+	raw   []byte   // LoadBytes 的内容，代替配置文件（不叠加环境变量，不支持监听）
+}
+
+// sources 最近一次 Load/LoadBytes 的来源
+var sources *sourceSet
+
+// resolveSources 确定环境与配置文件：
+// --config 指定时只读该文件；否则在查找目录中依次查找 config.yaml、config.<env>.yaml、config.local.yaml 并合并
+func resolveSources(args []string) (*sourceSet, error) {
+	flags := parseFlags(args)
+	env := firstNonEmpty(flags["env"], os.Getenv(EnvPrefix+"_ENV"), os.Getenv("GO_ENV"), "dev")
+	src := &sourceSet{env: env}
+
+	if file := flags["config"]; file != "" {
+		if _, err := os.Stat(file); err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		src.files = []string{absPath(file)}
+		return src, nil
+	}
+
+	names := []string{"config.yaml", "config." + env + ".yaml", "config.local.yaml"}
+	for _, name := range names {
+		for _, dir := range searchPaths {
+			file := filepath.Join(dir, name)
+			if info, err := os.Stat(file); err == nil && !info.IsDir() {
+				src.files = append(src.files, absPath(file))
+				break
+			}
+		}
+	}
+	if len(src.files) == 0 {
+		return nil, fmt.Errorf("failed to read config file: none of %s found in %v", strings.Join(names, ", "), searchPaths)
+	}
+	return src, nil
+}
+
+// read 合并配置文件与当前的配置层，再叠加环境变量
+func (s *sourceSet) read() (*AppConfig, map[string]any, error) {
+	return s.readWith(currentLayers())
+}
+
+func (s *sourceSet) readWith(ls []layer) (*AppConfig, map[string]any, error) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if s.raw != nil {
+		if err := v.ReadConfig(bytes.NewReader(s.raw)); err != nil {
+			return nil, nil, fmt.Errorf("failed to read config: %w", err)
+		}
+	}
+	for i, file := range s.files {
+		v.SetConfigFile(file)
+		read := v.MergeInConfig
+		if i == 0 {
+			read = v.ReadInConfig
+		}
+		if err := read(); err != nil {
+			return nil, nil, fmt.Errorf("failed to read config file %s: %w", file, err)
+		}
+	}
+	for _, l := range ls {
+		if err := checkLayerRefs(l); err != nil {
+			return nil, nil, err
+		}
+		if err := v.MergeConfig(bytes.NewReader(l.data)); err != nil {
+			return nil, nil, fmt.Errorf("failed to read config layer %s: %w", l.name, err)
+		}
+	}
+	if s.raw == nil {
+		v.SetDefault("app.env", s.env)
+		bindEnv(v)
+	}
+	return decode(v)
+}
+
+// decode 叠加 default 标签的默认值、解析 ${env:}/${file:}/enc: 引用后解码（包括注册的配置段）并校验
+func decode(v *viper.Viper) (*AppConfig, map[string]any, error) {
+	applyDefaults(v)
+	if err := resolveSecrets(v); err != nil {
+		return nil, nil, err
+	}
+	var config AppConfig
+	if err := v.Unmarshal(&config); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	settings := v.AllSettings()
+	sections, err := decodeSections(settings)
+	if err != nil {
+		return nil, nil, err
+	}
+	config.sections = sections
+	if err := Validate(&config); err != nil {
+		return nil, nil, err
+	}
+	return &config, settings, nil
+}
+
+// bindEnv 为 AppConfig 及注册的配置段的每个键绑定 NOMOYU_ 环境变量（文件中没有的键也能通过环境变量设置），
+// 其余自定义段通过 AutomaticEnv 覆盖文件中已有的键
+func bindEnv(v *viper.Viper) {
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	for _, key := range structKeys(reflect.TypeOf(AppConfig{}), "") {
+		_ = v.BindEnv(key)
+	}
+	for _, r := range registrations() {
+		for _, key := range structKeys(r.typ, r.name+".") {
+			_ = v.BindEnv(key)
+		}
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// structKeys 按 mapstructure 标签列出结构体的全部叶子键，如 server.tls.cert_file
+func structKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	walkFields(t, prefix, func(key string, _ reflect.StructField) {
+		keys = append(keys, key)
+	})
+	return keys
+}
+
+// walkFields 依次访问结构体的叶子字段（time.Duration 视为叶子），key 为小写的 mapstructure 路径
+func walkFields(t reflect.Type, prefix string, fn func(key string, f reflect.StructField)) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, _, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		key := strings.ToLower(prefix + tag)
+		if f.Type.Kind() == reflect.Struct && f.Type != durationType {
+			walkFields(f.Type, key+".", fn)
+			continue
+		}
+		fn(key, f)
+	}
+}
+
+// parseFlags 只识别 --config/--env（支持 -config、=value 两种写法），其余参数留给应用自己解析
+func parseFlags(args []string) map[string]string {
+	out := map[string]string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if name != "config" && name != "env" {
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		out[name] = value
+	}
+	return out
+}
+
+func absPath(file string) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		return abs
+	}
+	return file
+}
+
+func firstNonEmpty(vs ...string) string {
+	for _, v := range vs {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Env 当前加载使用的环境名（--env > NOMOYU_ENV > GO_ENV > dev）；LoadBytes 加载时为空
+func Env() string {
+	if sources == nil {
+		return ""
+	}
+	return sources.env
+}
+
+// Files 当前加载合并的配置文件，按覆盖顺序排列
+func Files() []string {
+	if sources == nil {
+		return nil
+	}
+	return append([]string(nil), sources.files...)
+}
+
+var (
+	errNoSources = errors.New("config: not loaded from files")
+	errNotLoaded = errors.New("config: not loaded by Load or LoadBytes")
+)
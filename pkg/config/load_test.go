@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, text string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func withArgs(t *testing.T, args ...string) {
+	t.Helper()
+	old := os.Args
+	t.Cleanup(func() { os.Args = old })
+	os.Args = append([]string{"app"}, args...)
+}
+
+func TestLoadLayeredFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeFile(t, "configs/config.yaml", "app: {name: base}\nserver: {port: 1000, host: h}\ndatabase: {user: u, password: p}\n")
+	writeFile(t, "configs/config.prod.yaml", "server: {port: 2000}\n")
+	writeFile(t, "config.local.yaml", "server: {host: local}\n")
+	writeFile(t, "other.yaml", "app: {name: other}\n")
+	t.Setenv("NOMOYU_DATABASE_PASSWORD", "secret")
+	t.Setenv("NOMOYU_SERVER_READ_TIMEOUT", "3s")
+	t.Setenv("NOMOYU_CORS_ALLOW_ORIGINS", "http://a,http://b")
+	t.Setenv("NOMOYU_REDIS_ADDR", "r:6379")
+
+	withArgs(t, "-v", "--env", "prod")
+	if err := Load(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(dir, "configs/config.yaml"),
+		filepath.Join(dir, "configs/config.prod.yaml"),
+		filepath.Join(dir, "config.local.yaml"),
+	}
+	if Env() != "prod" || !reflect.DeepEqual(Files(), want) {
+		t.Fatalf("env=%s files=%v", Env(), Files())
+	}
+	c := Current()
+	if c.App.Name != "base" || c.App.Env != "prod" || c.Server.Host != "local" || c.Server.Port != 2000 {
+		t.Errorf("files not merged: %+v %+v", c.App, c.Server)
+	}
+	// 环境变量覆盖文件，文件中没有的键也能设置
+	if c.Database.User != "u" || c.Database.Password != "secret" || c.Server.ReadTimeout != 3*time.Second ||
+		!reflect.DeepEqual(c.CORS.AllowOrigins, []string{"http://a", "http://b"}) || c.Redis.Addr != "r:6379" {
+		t.Errorf("env overrides: %+v %+v %v %s", c.Database, c.Server, c.CORS.AllowOrigins, c.Redis.Addr)
+	}
+
+	// --config 指定单个文件，不再查找分层文件
+	withArgs(t, "--config="+filepath.Join(dir, "other.yaml"))
+	if err := Load(); err != nil {
+		t.Fatal(err)
+	}
+	if Current().App.Name != "other" || Current().Server.Port != 3303 || len(Files()) != 1 {
+		t.Errorf("--config: files=%v %+v", Files(), Current().App)
+	}
+
+	withArgs(t, "--config", "missing.yaml")
+	if err := Load(); err == nil || !strings.Contains(err.Error(), "missing.yaml") {
+		t.Errorf("missing --config file: %v", err)
+	}
+	withArgs(t)
+	t.Chdir(t.TempDir())
+	if err := Load(); err == nil || !strings.Contains(err.Error(), "config.yaml") {
+		t.Errorf("no config file: %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// Section Register 注册的配置段的类型化访问器
+type Section[T any] struct {
+	name     string
+	defaults T
+}
+
+// registration 注册的配置段，defaults 为 T 类型的默认值
+type registration struct {
+	name     string
+	typ      reflect.Type
+	defaults reflect.Value
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]*registration{}
+)
+
+var sectionPattern = regexp.MustCompile(`^[a-z0-9_]+(\.[a-z0-9_]+)*$`)
+
+// Register 注册业务自定义的配置段（如 "payment"），T 为按 mapstructure 标签描述该段的结构体：
+// 与 AppConfig 一样从同一组配置文件、配置层与 NOMOYU_ 环境变量读取，支持 default/validate/secret 标签与
+// ${env:}/enc: 写法，校验失败时与其余配置的问题一起报告；defaults 中的非零值优先于 default 标签。
+// T 或 *T 实现 Validate() error 时还会调用它做跨字段校验。
+//
+// 需在 Load 之前调用（通常是包级变量）；名称不合法、与内置配置段或已注册的段冲突时 panic
+//
+//	var payment = config.Register("payment", PaymentConfig{Timeout: 5 * time.Second})
+//	payment.Get().MerchantID
+func Register[T any](name string, defaults T) *Section[T] {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("config: Register(%q): %s is not a struct", name, typ))
+	}
+	if !sectionPattern.MatchString(name) {
+		panic(fmt.Sprintf("config: Register(%q): name must be lowercase keys separated by dots", name))
+	}
+	root, _, _ := strings.Cut(name, ".")
+	if _, ok := fieldByTag(reflect.ValueOf(AppConfig{}), root); ok {
+		panic(fmt.Sprintf("config: Register(%q): %q is a built-in section", name, root))
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for other := range registry {
+		if other == name || strings.HasPrefix(other, name+".") || strings.HasPrefix(name, other+".") {
+			panic(fmt.Sprintf("config: Register(%q): conflicts with registered section %q", name, other))
+		}
+	}
+	registry[name] = &registration{name: name, typ: typ, defaults: reflect.ValueOf(defaults)}
+	return &Section[T]{name: name, defaults: defaults}
+}
+
+// Name 配置段的路径
+func (s *Section[T]) Name() string {
+	return s.name
+}
+
+// Get 返回当前配置快照中该段的值；配置尚未加载（或注册晚于加载）时返回 defaults
+func (s *Section[T]) Get() T {
+	if c := Current(); c != nil {
+		if v, ok := c.sections[s.name].(T); ok {
+			return v
+		}
+	}
+	return s.defaults
+}
+
+// OnChange 该段的值变化时回调，等价于 config.OnChange[T](name, fn)
+func (s *Section[T]) OnChange(fn func(old, new T)) (cancel func()) {
+	return OnChange(s.name, fn)
+}
+
+func registrations() []*registration {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	list := make([]*registration, 0, len(registry))
+	for _, r := range registry {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].name < list[j].name })
+	return list
+}
+
+// applySectionDefaults 注册 default 标签与 defaults 中非零值的默认值
+func applySectionDefaults(v *viper.Viper, r *registration) {
+	walkFields(r.typ, r.name+".", func(key string, f reflect.StructField) {
+		if def, ok := f.Tag.Lookup("default"); ok {
+			v.SetDefault(key, def)
+		}
+	})
+	walkValues(r.defaults, r.name+".", func(key string, val reflect.Value) {
+		if !val.IsZero() {
+			v.SetDefault(key, toPlain(val))
+		}
+	})
+}
+
+// decodeSections 从合并后的原始键值解码全部注册的配置段
+func decodeSections(settings map[string]any) (map[string]any, error) {
+	list := registrations()
+	if len(list) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]any, len(list))
+	for _, r := range list {
+		var raw any = settings
+		for _, key := range strings.Split(r.name, ".") {
+			m, _ := raw.(map[string]any)
+			raw = m[key]
+		}
+		ptr := reflect.New(r.typ)
+		if err := decodeMap(raw, ptr.Interface()); err != nil {
+			return nil, fmt.Errorf("failed to parse config section %s: %w", r.name, err)
+		}
+		out[r.name] = ptr.Elem().Interface()
+	}
+	return out, nil
+}
+
+// validateSections 按 validate 标签及 Validate() 方法校验注册的配置段，问题的键带上段名前缀
+func validateSections(c *AppConfig) ([]Issue, error) {
+	var issues []Issue
+	for _, r := range registrations() {
+		val, ok := c.sections[r.name]
+		if !ok {
+			continue
+		}
+		fieldIssues, err := validateStruct(val)
+		if err != nil {
+			return nil, err
+		}
+		for _, is := range fieldIssues {
+			is.Key = r.name + "." + is.Key
+			issues = append(issues, is)
+		}
+
+		ptr := reflect.New(r.typ)
+		ptr.Elem().Set(reflect.ValueOf(val))
+		if v, ok := ptr.Interface().(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				issues = append(issues, Issue{Key: r.name, Message: err.Error()})
+			}
+		}
+	}
+	return issues, nil
+}
+
+// sectionSecretKeys 注册的配置段中带 secret:"true" 标签的配置键
+func sectionSecretKeys() []string {
+	var keys []string
+	for _, r := range registrations() {
+		walkFields(r.typ, r.name+".", func(key string, f reflect.StructField) {
+			if f.Tag.Get("secret") == "true" {
+				keys = append(keys, key)
+			}
+		})
+	}
+	return keys
+}
+
+// Effective 当前生效的完整配置（脱敏），包括注册的配置段，键名与配置文件一致（配置中心等展示用）
+func Effective() map[string]any {
+	c := Current()
+	if c == nil {
+		return nil
+	}
+	out := ToMap(Masked(c))
+	for _, r := range registrations() {
+		val, ok := c.sections[r.name]
+		if !ok {
+			continue
+		}
+		ptr := reflect.New(r.typ)
+		ptr.Elem().Set(reflect.ValueOf(val))
+		maskStruct(ptr.Elem())
+
+		m := out
+		keys := strings.Split(r.name, ".")
+		for _, key := range keys[:len(keys)-1] {
+			next, ok := m[key].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				m[key] = next
+			}
+			m = next
+		}
+		m[keys[len(keys)-1]] = toPlain(ptr.Elem())
+	}
+	return out
+}
+
+// walkValues 与 walkFields 相同的规则访问结构体值的叶子字段
+func walkValues(v reflect.Value, prefix string, fn func(key string, val reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, _, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+		if tag == "" || tag == "-" || !f.IsExported() {
+			continue
+		}
+		key := strings.ToLower(prefix + tag)
+		if f.Type.Kind() == reflect.Struct && f.Type != durationType {
+			walkValues(v.Field(i), key+".", fn)
+			continue
+		}
+		fn(key, v.Field(i))
+	}
+}
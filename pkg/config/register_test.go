@@ -0,0 +1,141 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testPayment struct {
+	MerchantID string        `mapstructure:"merchant_id" validate:"required"`
+	Secret     string        `mapstructure:"secret" secret:"true"`
+	Timeout    time.Duration `mapstructure:"timeout" default:"3s"`
+	Retries    int           `mapstructure:"retries" default:"2" validate:"min=0,max=5"`
+	Currencies []string      `mapstructure:"currencies"`
+	Gateway    struct {
+		URL string `mapstructure:"url" validate:"omitempty,url"`
+	} `mapstructure:"gateway"`
+}
+
+func (p testPayment) Validate() error {
+	if p.Retries > 0 && p.Timeout < time.Second {
+		return errors.New("timeout must be >= 1s when retries > 0")
+	}
+	return nil
+}
+
+type testFeature struct {
+	Beta bool `mapstructure:"beta"`
+}
+
+// registerForTest 注册只在当前测试中生效的配置段（payment.merchant_id 必填，不能留在全局注册表里影响其他测试）
+func registerForTest[T any](t *testing.T, name string, defaults T) *Section[T] {
+	t.Helper()
+	s := Register(name, defaults)
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, name)
+		registryMu.Unlock()
+	})
+	return s
+}
+
+func TestRegisterRejectsBadNames(t *testing.T) {
+	registerForTest(t, "payment", testFeature{})
+	for _, name := range []string{"server", "Payment", "pay-ment", "payment", "payment.x"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Register(%q): expected panic", name)
+				}
+			}()
+			Register(name, testFeature{})
+		}()
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Register with non-struct type: expected panic")
+			}
+		}()
+		Register("flag", "x")
+	}()
+}
+
+func TestRegisterSection(t *testing.T) {
+	payment := registerForTest(t, "payment", testPayment{Timeout: 5 * time.Second, Currencies: []string{"CNY"}})
+	feature := registerForTest(t, "biz.feature", testFeature{})
+	if payment.Get().Timeout != 5*time.Second {
+		t.Fatalf("before Load: %+v", payment.Get())
+	}
+
+	// 与内置配置的问题一起报告
+	err := LoadBytes([]byte("log: {level: nope}\npayment: {retries: 9, gateway: {url: x}}\n"))
+	for _, key := range []string{"log.level", "payment.merchant_id", "payment.retries", "payment.gateway.url"} {
+		if err == nil || !strings.Contains(err.Error(), key) {
+			t.Errorf("missing issue for %s: %v", key, err)
+		}
+	}
+	err = LoadBytes([]byte("payment: {merchant_id: m, timeout: 100ms}\n"))
+	if err == nil || !strings.Contains(err.Error(), "payment: timeout must be") {
+		t.Fatalf("Validate(): %v", err)
+	}
+
+	// 配置文件、${env:} 引用与 NOMOYU_ 环境变量；defaults 中的非零值优先于 default 标签
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, file, "payment:\n  merchant_id: m1\n  secret: ${env:PAY_SECRET}\n")
+	withArgs(t, "--config", file)
+	t.Setenv("PAY_SECRET", "s3cr3t")
+	t.Setenv("NOMOYU_PAYMENT_RETRIES", "4")
+	if err := Load(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = SetLayer("t", nil) })
+	p := payment.Get()
+	if p.MerchantID != "m1" || p.Secret != "s3cr3t" || p.Timeout != 5*time.Second || p.Retries != 4 || len(p.Currencies) != 1 {
+		t.Fatalf("loaded: %+v", p)
+	}
+	if feature.Get().Beta {
+		t.Fatal("feature.beta: want false")
+	}
+
+	var got []testPayment
+	cancel := payment.OnChange(func(old, new testPayment) { got = append(got, new) })
+	defer cancel()
+	var ptrGot *testPayment
+	cancelPtr := OnChange("payment", func(old, new *testPayment) { ptrGot = new })
+	defer cancelPtr()
+	fired := 0
+	cancelFeature := OnChange("biz.feature", func(old, new testFeature) { fired++ })
+	defer cancelFeature()
+
+	if err := SetLayer("t", []byte("payment: {merchant_id: m2}\n")); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].MerchantID != "m2" || ptrGot == nil || ptrGot.MerchantID != "m2" || fired != 0 {
+		t.Fatalf("OnChange: %+v %+v fired=%d", got, ptrGot, fired)
+	}
+	if err := SetLayer("t", []byte("payment: {merchant_id: ''}\n")); err == nil || payment.Get().MerchantID != "m2" {
+		t.Fatalf("invalid layer: %v, merchant_id %q", err, payment.Get().MerchantID)
+	}
+	if err := SetLayer("t", []byte("biz: {feature: {beta: true}}\n")); err != nil || !feature.Get().Beta || fired != 1 {
+		t.Fatalf("nested section: %v, fired=%d", err, fired)
+	}
+
+	// secret 字段脱敏，快照中的值不受影响
+	if !slices.Contains(SecretKeys(), "payment.secret") {
+		t.Errorf("SecretKeys: %v", SecretKeys())
+	}
+	eff := Effective()
+	pm, _ := eff["payment"].(map[string]any)
+	biz, _ := eff["biz"].(map[string]any)
+	if pm["secret"] != SecretMask || pm["timeout"] != "5s" || biz["feature"] == nil || eff["server"] == nil {
+		t.Fatalf("Effective: %v", eff)
+	}
+	if payment.Get().Secret != "s3cr3t" {
+		t.Fatal("mask leaked into the snapshot")
+	}
+}
@@ -0,0 +1,348 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// MasterKeyEnv 解密 enc: 配置值的主密钥（base64 编码的 16/24/32 字节 AES 密钥）所在的环境变量
+const MasterKeyEnv = "NOMOYU_MASTER_KEY"
+
+// encPrefix 加密值前缀：enc:<base64(nonce + AES-GCM 密文)>
+const encPrefix = "enc:"
+
+// SecretMask 展示、日志中代替 secret 字段的值
+const SecretMask = "******"
+
+// refPattern 匹配 ${env:NAME}、${env:NAME:-默认值}、${file:/run/secrets/x}
+var refPattern = regexp.MustCompile(`\$\{(env|file):([^}]*)\}`)
+
+// resolveSecrets 解析所有字符串值中的 ${env:}/${file:} 引用与 enc: 加密值，全部问题汇总后一次返回
+func resolveSecrets(v *viper.Viper) error {
+	out := &ValidationError{}
+	for _, key := range v.AllKeys() {
+		val, changed, err := resolveValue(v.Get(key))
+		if err != nil {
+			out.Issues = append(out.Issues, Issue{Key: key, Message: err.Error()})
+			continue
+		}
+		if changed {
+			v.Set(key, val)
+		}
+	}
+	if len(out.Issues) > 0 {
+		return out
+	}
+	return nil
+}
+
+func resolveValue(val any) (any, bool, error) {
+	switch x := val.(type) {
+	case string:
+		s, err := resolveString(x)
+		return s, err == nil && s != x, err
+	case []any:
+		items := make([]any, len(x))
+		changed := false
+		for i, item := range x {
+			r, c, err := resolveValue(item)
+			if err != nil {
+				return nil, false, fmt.Errorf("[%d]: %w", i, err)
+			}
+			items[i], changed = r, changed || c
+		}
+		return items, changed, nil
+	case []string:
+		items := make([]string, len(x))
+		changed := false
+		for i, item := range x {
+			r, err := resolveString(item)
+			if err != nil {
+				return nil, false, fmt.Errorf("[%d]: %w", i, err)
+			}
+			items[i], changed = r, changed || r != item
+		}
+		return items, changed, nil
+	}
+	return val, false, nil
+}
+
+// checkLayerRefs 配置层（配置中心、远程配置等在线编辑的来源）中只有 secret 字段可以使用 ${env:}/${file:}/enc:，
+// 否则能编辑配置的人可以把环境变量、本机文件或其他字段的密钥明文读到不脱敏的字段里展示出来
+func checkLayerRefs(l layer) error {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(l.data)); err != nil {
+		return fmt.Errorf("failed to read config layer %s: %w", l.name, err)
+	}
+	secret := map[string]bool{}
+	for _, key := range SecretKeys() {
+		secret[key] = true
+	}
+	out := &ValidationError{}
+	for _, key := range v.AllKeys() {
+		if !secret[key] && hasRef(v.Get(key)) {
+			out.Issues = append(out.Issues, Issue{Key: key, Message: fmt.Sprintf(
+				"${env:}/${file:}/enc: are only allowed in local config files or for secret fields (layer %s)", l.name)})
+		}
+	}
+	if len(out.Issues) > 0 {
+		return out
+	}
+	return nil
+}
+
+func hasRef(val any) bool {
+	switch x := val.(type) {
+	case string:
+		return strings.HasPrefix(x, encPrefix) || refPattern.MatchString(x)
+	case []any:
+		for _, item := range x {
+			if hasRef(item) {
+				return true
+			}
+		}
+	case []string:
+		for _, item := range x {
+			if hasRef(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolveString enc: 前缀表示整个值是密文；否则替换其中的 ${env:}/${file:} 引用
+func resolveString(s string) (string, error) {
+	if strings.HasPrefix(s, encPrefix) {
+		return Decrypt(s)
+	}
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var firstErr error
+	out := refPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		m := refPattern.FindStringSubmatch(ref)
+		val, err := resolveRef(m[1], m[2])
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		return val
+	})
+	return out, firstErr
+}
+
+func resolveRef(kind, arg string) (string, error) {
+	name, def, hasDef := strings.Cut(arg, ":-")
+	switch kind {
+	case "env":
+		if val, ok := os.LookupEnv(name); ok {
+			return val, nil
+		}
+		if hasDef {
+			return def, nil
+		}
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	default: // file
+		data, err := os.ReadFile(name)
+		if err != nil {
+			if hasDef && errors.Is(err, os.ErrNotExist) {
+				return def, nil
+			}
+			return "", fmt.Errorf("read secret file: %w", err)
+		}
+		// secret 文件通常以换行结尾
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+}
+
+// Encrypt 用主密钥（未传时取环境变量 NOMOYU_MASTER_KEY）加密配置值，返回可直接写入配置文件的 enc:... 字符串
+func Encrypt(plaintext string, key ...[]byte) (string, error) {
+	aead, err := masterAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密 enc:... 配置值，key 规则同 Encrypt
+func Decrypt(value string, key ...[]byte) (string, error) {
+	aead, err := masterAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encPrefix))
+	if err != nil || len(raw) < aead.NonceSize() {
+		return "", errors.New("malformed enc: value")
+	}
+	nonce, sealed := raw[:aead.NonceSize()], raw[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", errors.New("cannot decrypt enc: value (wrong master key?)")
+	}
+	return string(plain), nil
+}
+
+// GenerateMasterKey 生成 base64 编码的 32 字节主密钥
+func GenerateMasterKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+func masterAEAD(key [][]byte) (cipher.AEAD, error) {
+	var k []byte
+	if len(key) > 0 {
+		k = key[0]
+	} else {
+		encoded := os.Getenv(MasterKeyEnv)
+		if encoded == "" {
+			return nil, fmt.Errorf("%s is not set", MasterKeyEnv)
+		}
+		var err error
+		if k, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+			return nil, fmt.Errorf("%s is not valid base64: %w", MasterKeyEnv, err)
+		}
+	}
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, fmt.Errorf("invalid master key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// Masked 返回 v 的副本，其中带 secret:"true" 标签的非空字段替换为 ******（展示、日志配置时使用）；
+// v 可以是结构体或结构体指针，其余类型原样返回
+func Masked[T any](v T) T {
+	rv := reflect.ValueOf(&v).Elem()
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+			return v
+		}
+		cp := reflect.New(rv.Elem().Type())
+		cp.Elem().Set(rv.Elem())
+		maskStruct(cp.Elem())
+		return cp.Interface().(T)
+	}
+	if rv.Kind() == reflect.Struct {
+		maskStruct(rv)
+	}
+	return v
+}
+
+// maskStruct 原地处理结构体副本：secret 字段置为 SecretMask，嵌套结构体递归（切片重新分配，不影响原值）
+func maskStruct(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := v.Field(i)
+		if !t.Field(i).IsExported() {
+			continue
+		}
+		if t.Field(i).Tag.Get("secret") == "true" {
+			switch {
+			case f.Kind() == reflect.String && f.Len() > 0:
+				f.SetString(SecretMask)
+			case f.Kind() == reflect.Slice && f.Type().Elem().Kind() == reflect.String && f.Len() > 0:
+				masked := reflect.MakeSlice(f.Type(), f.Len(), f.Len())
+				for j := 0; j < f.Len(); j++ {
+					masked.Index(j).SetString(SecretMask)
+				}
+				f.Set(masked)
+			}
+			continue
+		}
+		switch {
+		case f.Kind() == reflect.Struct:
+			maskStruct(f)
+		case f.Kind() == reflect.Slice && f.Type().Elem().Kind() == reflect.Struct && f.Len() > 0:
+			cp := reflect.MakeSlice(f.Type(), f.Len(), f.Len())
+			reflect.Copy(cp, f)
+			for j := 0; j < cp.Len(); j++ {
+				maskStruct(cp.Index(j))
+			}
+			f.Set(cp)
+		}
+	}
+}
+
+// SecretKeys 返回 AppConfig 及注册的配置段中带 secret:"true" 标签的配置键，如 database.password
+func SecretKeys() []string {
+	var keys []string
+	walkFields(reflect.TypeOf(AppConfig{}), "", func(key string, f reflect.StructField) {
+		if f.Tag.Get("secret") == "true" {
+			keys = append(keys, key)
+		}
+	})
+	return append(keys, sectionSecretKeys()...)
+}
+
+// ToMap 按 mapstructure 标签把配置结构体转换为嵌套 map（time.Duration 转为 30s 这样的字符串），
+// 用于以配置文件中的键名输出 JSON/YAML；通常先用 Masked 脱敏
+func ToMap(v any) map[string]any {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	out, _ := toPlain(rv).(map[string]any)
+	return out
+}
+
+func toPlain(v reflect.Value) any {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return toPlain(v.Elem())
+	case reflect.Struct:
+		m := map[string]any{}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			tag, _, _ := strings.Cut(t.Field(i).Tag.Get("mapstructure"), ",")
+			if tag == "" || tag == "-" || !t.Field(i).IsExported() {
+				continue
+			}
+			m[tag] = toPlain(v.Field(i))
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return []any{}
+		}
+		items := make([]any, v.Len())
+		for i := range items {
+			items[i] = toPlain(v.Index(i))
+		}
+		return items
+	case reflect.Map:
+		m := map[string]any{}
+		iter := v.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = toPlain(iter.Value())
+		}
+		return m
+	}
+	return v.Interface()
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	key, err := GenerateMasterKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(MasterKeyEnv, key)
+	t.Setenv("S_USER", "bob")
+	enc, err := Encrypt("pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	y := "database:\n  dialect: sqlite\n  dbname: a\n  user: \"${env:S_USER}-x\"\n  password: \"" + enc + "\"\n" +
+		"cors:\n  enabled: true\n  allow_origins: [\"${env:S_ORIGIN:-https://a.com}\"]\nauth:\n  jwt:\n    secret: zzz\n"
+	if err := LoadBytes([]byte(y)); err != nil {
+		t.Fatal(err)
+	}
+	c := Current()
+	if c.Database.User != "bob-x" || c.Database.Password != "pw" || c.CORS.AllowOrigins[0] != "https://a.com" {
+		t.Fatalf("unexpected %+v %+v", c.Database, c.CORS)
+	}
+
+	m := Masked(c)
+	if m.Database.Password != SecretMask || m.Auth.JWT.Secret != SecretMask || m.Redis.Password != "" {
+		t.Fatalf("not masked: %+v", m)
+	}
+	if c.Auth.JWT.Secret != "zzz" {
+		t.Fatal("Masked modified the original")
+	}
+}
+
+func TestResolveSecretsErrors(t *testing.T) {
+	err := LoadBytes([]byte("database:\n  user: ${env:NOMOYU_TEST_MISSING}\n  password: enc:!!!\n"))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, want := range []string{"database.user", "NOMOYU_TEST_MISSING", "database.password"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
+
+func TestLayerRefsOnlyForSecrets(t *testing.T) {
+	t.Setenv("NOMOYU_TEST_SECRET", "hunter2")
+	if err := LoadBytes([]byte("app:\n  name: a\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, doc := range []string{
+		"app:\n  version: ${env:NOMOYU_TEST_SECRET}\n",
+		"app:\n  version: ${file:/etc/hostname}\n",
+		"app:\n  version: enc:AAAA\n",
+		"cors:\n  allow_origins: [\"x${env:NOMOYU_TEST_SECRET}\"]\n",
+	} {
+		err := SetLayer("test", []byte(doc))
+		if err == nil || !strings.Contains(err.Error(), "only allowed") {
+			t.Errorf("SetLayer(%q) = %v, want rejection", doc, err)
+		}
+	}
+	if Current().App.Version != "" {
+		t.Fatalf("rejected layer applied: %q", Current().App.Version)
+	}
+
+	if err := SetLayer("test", []byte("database:\n  password: ${env:NOMOYU_TEST_SECRET}\n")); err != nil {
+		t.Fatal(err)
+	}
+	if Current().Database.Password != "hunter2" {
+		t.Fatalf("secret field not resolved: %q", Current().Database.Password)
+	}
+}
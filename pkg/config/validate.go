@@ -0,0 +1,193 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/viper"
+)
+
+// Issue 一条校验失败：Key 为 mapstructure 路径（如 server.port）
+type Issue struct {
+	Key     string
+	Message string
+}
+
+// ValidationError 汇总全部校验失败，启动时一次性报告
+type ValidationError struct {
+	Issues []Issue
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid config (%d errors):", len(e.Issues))
+	for _, is := range e.Issues {
+		fmt.Fprintf(&b, "\n  - %s: %s", is.Key, is.Message)
+	}
+	return b.String()
+}
+
+// Validate 按 AppConfig 的 validate 标签及跨字段规则（启用 TLS 需要证书、数据库需要 host 等）校验配置，
+// 同时校验 Register 注册的配置段；
+// 返回 *ValidationError；Load、LoadBytes、Reload 都会调用
+func Validate(c *AppConfig) error {
+	issues, err := validateStruct(c)
+	if err != nil {
+		return err
+	}
+	sectionIssues, err := validateSections(c)
+	if err != nil {
+		return err
+	}
+	issues = append(issues, sectionIssues...)
+	if len(issues) == 0 {
+		return nil
+	}
+	return &ValidationError{Issues: issues}
+}
+
+// validateStruct 按 validate 标签校验结构体，Key 为相对该结构体的 mapstructure 路径
+func validateStruct(s any) ([]Issue, error) {
+	err := validate().Struct(s)
+	if err == nil {
+		return nil, nil
+	}
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return nil, err
+	}
+	issues := make([]Issue, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		_, key, _ := strings.Cut(fe.Namespace(), ".")
+		issues = append(issues, Issue{Key: key, Message: issueMessage(fe)})
+	}
+	return issues, nil
+}
+
+var (
+	validateOnce sync.Once
+	validateInst *validator.Validate
+)
+
+func validate() *validator.Validate {
+	validateOnce.Do(func() {
+		v := validator.New()
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name, _, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+		_ = v.RegisterValidation("filemode", func(fl validator.FieldLevel) bool {
+			_, err := strconv.ParseUint(fl.Field().String(), 8, 32)
+			return err == nil
+		})
+		v.RegisterStructValidation(validateTLS, ServerTLS{})
+		v.RegisterStructValidation(validateDatabase, Database{})
+		v.RegisterStructValidation(validateRedis, RedisConfig{})
+		v.RegisterStructValidation(validateAuth, AuthConfig{})
+		v.RegisterStructValidation(validateCORS, CORS{})
+		validateInst = v
+	})
+	return validateInst
+}
+
+// 跨字段规则统一用 required_when 上报，param 为可读的条件
+
+func validateTLS(sl validator.StructLevel) {
+	c := sl.Current().Interface().(ServerTLS)
+	if !c.Enabled {
+		return
+	}
+	requireWhen(sl, c.CertFile, "cert_file", "server.tls.enabled is true")
+	requireWhen(sl, c.KeyFile, "key_file", "server.tls.enabled is true")
+}
+
+func validateDatabase(sl validator.StructLevel) {
+	c := sl.Current().Interface().(Database)
+	if c.Dialect == "" {
+		if c.Host != "" {
+			requireWhen(sl, c.Dialect, "dialect", "database.host is set")
+		}
+		return
+	}
+	if c.Dialect != "sqlite" {
+		requireWhen(sl, c.Host, "host", "database.dialect is "+c.Dialect)
+	}
+	requireWhen(sl, c.DBName, "dbname", "database.dialect is set")
+}
+
+func validateRedis(sl validator.StructLevel) {
+	c := sl.Current().Interface().(RedisConfig)
+	if c.Mode == "cluster" && len(c.Addrs) == 0 {
+		sl.ReportError(c.Addrs, "addrs", "Addrs", "required_when", "redis.mode is cluster")
+	}
+}
+
+func validateAuth(sl validator.StructLevel) {
+	c := sl.Current().Interface().(AuthConfig)
+	if c.Enabled && c.Mode == "jwt" {
+		requireWhen(sl, c.JWT.Secret, "jwt.secret", "auth.mode is jwt")
+	}
+}
+
+func validateCORS(sl validator.StructLevel) {
+	c := sl.Current().Interface().(CORS)
+	if c.Enabled && len(c.AllowOrigins) == 0 {
+		sl.ReportError(c.AllowOrigins, "allow_origins", "AllowOrigins", "required_when", "cors.enabled is true")
+	}
+}
+
+func requireWhen(sl validator.StructLevel, v, field, cond string) {
+	if v == "" {
+		sl.ReportError(v, field, field, "required_when", cond)
+	}
+}
+
+func issueMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_with":
+		return "is required when " + strings.ToLower(fe.Param()) + " is set"
+	case "required_when":
+		return "is required when " + fe.Param()
+	case "oneof":
+		return fmt.Sprintf("must be one of [%s], got %q", fe.Param(), fmt.Sprint(fe.Value()))
+	case "min":
+		return fmt.Sprintf("must be >= %s, got %v", fe.Param(), fe.Value())
+	case "gt":
+		return fmt.Sprintf("must be > %s, got %v", fe.Param(), fe.Value())
+	case "max":
+		return fmt.Sprintf("must be <= %s, got %v", fe.Param(), fe.Value())
+	case "hostname_port":
+		return fmt.Sprintf("must be host:port, got %q", fe.Value())
+	case "url":
+		return fmt.Sprintf("must be an absolute URL, got %q", fe.Value())
+	case "startswith":
+		return fmt.Sprintf("must start with %q, got %q", fe.Param(), fe.Value())
+	case "cidr|ip":
+		return fmt.Sprintf("must be an IP or CIDR such as 10.0.0.0/8, got %q", fe.Value())
+	case "filemode":
+		return fmt.Sprintf("must be an octal file mode such as 0660, got %q", fe.Value())
+	}
+	return fmt.Sprintf("failed %q validation, got %v", fe.Tag(), fe.Value())
+}
+
+// applyDefaults 把 AppConfig 及注册的配置段的 default 标签注册为 viper 默认值，文件与环境变量中的值优先
+func applyDefaults(v *viper.Viper) {
+	walkFields(reflect.TypeOf(AppConfig{}), "", func(key string, f reflect.StructField) {
+		if def, ok := f.Tag.Lookup("default"); ok {
+			v.SetDefault(key, def)
+		}
+	})
+	for _, r := range registrations() {
+		applySectionDefaults(v, r)
+	}
+}
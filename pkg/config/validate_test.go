@@ -0,0 +1,62 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaults(t *testing.T) {
+	if err := LoadBytes([]byte("app:\n  name: x\n")); err != nil {
+		t.Fatal(err)
+	}
+	c := Current()
+	if c.Server.Port != 3303 || c.Server.WriteTimeout != 30*time.Second || c.Log.Path != "./logs" || c.Log.Level != "info" ||
+		len(c.CORS.AllowMethods) != 5 || c.WebSocket.MaxMessageSize != 65536 || c.RateLimit.Burst != 200 {
+		t.Fatalf("defaults not applied: %+v %+v", c.Server, c.CORS)
+	}
+}
+
+func TestValidateReportsAllIssues(t *testing.T) {
+	err := LoadBytes([]byte("server:\n  port: -1\nlog:\n  level: x\ncors:\n  enabled: true\nauth:\n  enabled: true\n"))
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("want *ValidationError, got %v", err)
+	}
+	got := map[string]string{}
+	for _, is := range ve.Issues {
+		got[is.Key] = is.Message
+	}
+	want := map[string]string{
+		"server.port":        "must be >= 0",
+		"log.level":          "must be one of",
+		"cors.allow_origins": "is required when cors.enabled is true",
+		"auth.jwt.secret":    "is required when auth.mode is jwt",
+	}
+	if len(got) != len(want) {
+		t.Errorf("issues: %v", got)
+	}
+	for key, msg := range want {
+		if !strings.Contains(got[key], msg) {
+			t.Errorf("%s: got %q, want %q", key, got[key], msg)
+		}
+	}
+}
+
+func TestReloadKeepsConfigOnInvalidFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "c.yaml")
+	writeFile(t, file, "server:\n  port: 8081\n")
+	withArgs(t, "--config", file)
+	if err := Load(); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, file, "server:\n  port: 99999\n")
+	if err := Reload(); err == nil || !strings.Contains(err.Error(), "server.port") {
+		t.Fatalf("reload: %v", err)
+	}
+	if Current().Server.Port != 8081 {
+		t.Fatalf("invalid file replaced config: port %d", Current().Server.Port)
+	}
+}
@@ -0,0 +1,328 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// reloadDebounce 编辑器保存时常连续触发多次写事件，合并后再重新加载
+const reloadDebounce = 200 * time.Millisecond
+
+// snapshot 一次加载的结果：结构化配置 + 原始键值（供 AppConfig 之外的自定义段使用）
+type snapshot struct {
+	conf     *AppConfig
+	settings map[string]any
+}
+
+type subscriber struct {
+	id      uint64
+	section string
+	notify  func(old, new *snapshot)
+}
+
+var (
+	current atomic.Pointer[snapshot]
+
+	subsMu sync.Mutex
+	subs   []subscriber
+	subSeq uint64
+
+	reloadMu sync.Mutex
+
+	reloadErrorHandler atomic.Value // func(error)
+)
+
+// Current 返回当前配置快照（热更新时整体原子替换，读取方不会看到更新了一半的配置），可在任意 goroutine 中调用
+func Current() *AppConfig {
+	if s := current.Load(); s != nil {
+		return s.conf
+	}
+	return Conf
+}
+
+// Set 直接替换 Conf 与当前配置，不通知订阅者（测试恢复配置等场景）
+func Set(c *AppConfig) {
+	Conf = c
+	store(c, nil)
+}
+
+// store 只替换快照：热更新在后台 goroutine 中执行，不能写 Conf（读取方没有同步）
+func store(c *AppConfig, settings map[string]any) {
+	current.Store(&snapshot{conf: c, settings: settings})
+}
+
+// OnChange 订阅配置段的变化，section 为 mapstructure 路径（如 "log"、"server.tls"，空字符串表示整个配置），
+// T 为该段的类型（如 config.Log）；只有该段的值发生变化时才回调。返回取消订阅的函数
+//
+// AppConfig 中没有的段按原始键值解码为 T，可用于业务自定义配置
+func OnChange[T any](section string, fn func(old, new T)) (cancel func()) {
+	if s := current.Load(); s != nil {
+		// 尽早暴露类型写错的问题
+		if _, err := extract[T](s, section); err != nil {
+			panic(err)
+		}
+	}
+
+	subsMu.Lock()
+	defer subsMu.Unlock()
+	subSeq++
+	id := subSeq
+	subs = append(subs, subscriber{
+		id:      id,
+		section: section,
+		notify: func(old, new *snapshot) {
+			ov, err1 := extract[T](old, section)
+			nv, err2 := extract[T](new, section)
+			if err1 != nil || err2 != nil || reflect.DeepEqual(ov, nv) {
+				return
+			}
+			fn(ov, nv)
+		},
+	})
+	return func() {
+		subsMu.Lock()
+		defer subsMu.Unlock()
+		for i, s := range subs {
+			if s.id == id {
+				subs = append(subs[:i:i], subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// OnReloadError 设置热更新失败（文件格式错误等）时的处理函数，默认输出到标准日志
+func OnReloadError(fn func(err error)) {
+	reloadErrorHandler.Store(fn)
+}
+
+// Watch 监听 Load 合并的所有配置文件，任一变化时按同样的规则重新加载；新配置无法解析时保留当前配置并报告错误。
+// 返回的 stop 停止监听并等待进行中的重新加载结束
+//
+// 监听的是文件所在目录：编辑器保存、k8s ConfigMap 更新（替换 ..data 软链接）都会替换文件而不是原地写入
+func Watch() (stop func(), err error) {
+	src := sources
+	if src == nil || len(src.files) == 0 {
+		return nil, fmt.Errorf("config: cannot watch: %w", errNoSources)
+	}
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("config: cannot watch: %w", err)
+	}
+	files := make(map[string]string, len(src.files)) // 文件 -> 软链接解析后的路径
+	dirs := map[string]bool{}
+	for _, file := range src.files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			w.Close()
+			return nil, fmt.Errorf("config: cannot watch %s: %w", file, err)
+		}
+		files[abs], _ = filepath.EvalSymlinks(abs)
+		dirs[filepath.Dir(abs)] = true
+	}
+	for dir := range dirs {
+		if err := w.Add(dir); err != nil {
+			w.Close()
+			return nil, fmt.Errorf("config: cannot watch %s: %w", dir, err)
+		}
+	}
+
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		var debounce <-chan time.Time
+		for {
+			select {
+			case <-done:
+				return
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if changed(files, ev) {
+					debounce = time.After(reloadDebounce)
+				}
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				reportReloadError(fmt.Errorf("config: watch: %w", err))
+			case <-debounce:
+				debounce = nil
+				if err := Reload(); err != nil {
+					reportReloadError(err)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			w.Close()
+			<-exited
+		})
+	}, nil
+}
+
+// changed 事件是否涉及监听的文件：文件本身被写入/替换，或其软链接指向了新的文件
+func changed(files map[string]string, ev fsnotify.Event) bool {
+	if !ev.Has(fsnotify.Write) && !ev.Has(fsnotify.Create) && !ev.Has(fsnotify.Remove) && !ev.Has(fsnotify.Rename) {
+		return false
+	}
+	name := filepath.Clean(ev.Name)
+	hit := false
+	for file, real := range files {
+		now, _ := filepath.EvalSymlinks(file)
+		if file == name || now != real {
+			files[file] = now
+			hit = true
+		}
+	}
+	return hit
+}
+
+// Reload 重新读取配置文件、配置层与环境变量并原子替换当前配置，随后通知发生变化的配置段的订阅者
+func Reload() error {
+	src := sources
+	if src == nil {
+		return fmt.Errorf("config: cannot reload: %w", errNotLoaded)
+	}
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	c, settings, err := src.read()
+	if err != nil {
+		return fmt.Errorf("config: reload rejected: %w", err)
+	}
+	replace(c, settings)
+	return nil
+}
+
+// replace 替换当前配置并通知订阅者，调用方持有 reloadMu
+func replace(c *AppConfig, settings map[string]any) {
+	old := current.Load()
+	store(c, settings)
+	if old != nil {
+		notify(old, current.Load())
+	}
+}
+
+// notify 依次回调订阅者，单个订阅者 panic 不影响其他订阅者
+func notify(old, new *snapshot) {
+	subsMu.Lock()
+	list := append([]subscriber(nil), subs...)
+	subsMu.Unlock()
+
+	for _, s := range list {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					reportReloadError(fmt.Errorf("config: OnChange(%q) panic: %v", s.section, r))
+				}
+			}()
+			s.notify(old, new)
+		}()
+	}
+}
+
+func reportReloadError(err error) {
+	if fn, ok := reloadErrorHandler.Load().(func(error)); ok && fn != nil {
+		fn(err)
+		return
+	}
+	log.Printf("%v", err)
+}
+
+// extract 按 mapstructure 路径取出配置段：优先取 AppConfig 的字段与注册的配置段，没有时从原始键值解码
+func extract[T any](s *snapshot, section string) (T, error) {
+	var zero T
+	want := reflect.TypeOf((*T)(nil)).Elem()
+
+	v := reflect.ValueOf(s.conf).Elem()
+	found := true
+	if section != "" {
+		for _, key := range strings.Split(section, ".") {
+			if v.Kind() != reflect.Struct {
+				found = false
+				break
+			}
+			f, ok := fieldByTag(v, key)
+			if !ok {
+				found = false
+				break
+			}
+			v = f
+		}
+	}
+	if !found {
+		var val any
+		if val, found = s.conf.sections[section]; found {
+			v = reflect.ValueOf(val)
+		}
+	}
+	if found {
+		switch {
+		case v.Type().AssignableTo(want):
+			return v.Interface().(T), nil
+		case reflect.PointerTo(v.Type()).AssignableTo(want):
+			// 返回副本的指针，订阅者修改不会影响当前配置
+			cp := reflect.New(v.Type())
+			cp.Elem().Set(v)
+			return cp.Interface().(T), nil
+		}
+		return zero, fmt.Errorf("config: section %q is %s, not %s", section, v.Type(), want)
+	}
+
+	var raw any = s.settings
+	for _, key := range strings.Split(section, ".") {
+		m, ok := raw.(map[string]any)
+		if !ok {
+			return zero, nil
+		}
+		raw = m[strings.ToLower(key)]
+	}
+	var out T
+	if err := decodeMap(raw, &out); err != nil {
+		return zero, fmt.Errorf("config: decode section %q: %w", section, err)
+	}
+	return out, nil
+}
+
+// decodeMap 与 viper.Unmarshal 相同的规则（弱类型、30s 转 Duration、逗号分隔转切片）把原始键值解码到 out
+func decodeMap(raw any, out any) error {
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           out,
+		WeaklyTypedInput: true,
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+	})
+	if err != nil {
+		return err
+	}
+	return dec.Decode(raw)
+}
+
+func fieldByTag(v reflect.Value, key string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("mapstructure"), ",")
+		if strings.EqualFold(tag, key) {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,268 @@
+package configcenter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+)
+
+// LayerName 本应用的配置中心文档在 config 中的配置层名（叠加在配置文件之上、环境变量之下）
+const LayerName = "config-center"
+
+// ErrInvalid 提交的文档无法保存：YAML 格式错误、校验不通过等，具体原因见错误信息
+var ErrInvalid = errors.New("configcenter: invalid config")
+
+// Center 管理各 app/env 的配置文档；本应用（app/env 与当前配置一致）的文档保存后立即通过配置层生效
+type Center struct {
+	store    *Store
+	app, env string
+	mu       sync.Mutex // 串行化修改，保证版本与生效的配置一致
+
+	watchMu  sync.Mutex
+	watchers map[string]chan struct{} // app/env -> 下次保存时关闭
+}
+
+// Change 一次修改，App/Env 为空时表示本应用
+type Change struct {
+	App     string
+	Env     string
+	Content []byte
+	Comment string
+	Actor   string
+	IP      string
+}
+
+// New 创建配置中心，app/env 为本应用的文档
+func New(store *Store, app, env string) *Center {
+	return &Center{store: store, app: app, env: env, watchers: map[string]chan struct{}{}}
+}
+
+func (c *Center) Store() *Store { return c.store }
+
+// Target 返回本应用文档的 app/env
+func (c *Center) Target() (app, env string) { return c.app, c.env }
+
+// Apply 把本应用的最新版本设为配置层（启动时调用）
+func (c *Center) Apply() error {
+	_, data, err := c.store.Latest(c.app, c.env)
+	if err != nil || data == nil {
+		return err
+	}
+	return config.SetLayer(LayerName, data)
+}
+
+// Save 保存新版本：还原未修改的脱敏密钥、校验，本应用的文档先生效再落盘，最后记录审计
+func (c *Center) Save(ch Change) (Version, error) {
+	return c.save(ch, "save")
+}
+
+// Rollback 以指定版本的内容保存为新版本（历史保持线性，回滚本身也可回滚）
+func (c *Center) Rollback(app, env string, version int, actor, ip string) (Version, error) {
+	app, env = c.target(app, env)
+	data, err := c.store.Get(app, env, version)
+	if err != nil {
+		return Version{}, err
+	}
+	return c.save(Change{
+		App:     app,
+		Env:     env,
+		Content: data,
+		Comment: fmt.Sprintf("rollback to v%d", version),
+		Actor:   actor,
+		IP:      ip,
+	}, "rollback")
+}
+
+func (c *Center) save(ch Change, action string) (Version, error) {
+	app, env := c.target(ch.App, ch.Env)
+	if ch.Actor == "" {
+		ch.Actor = "anonymous"
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	last, prev, err := c.store.Latest(app, env)
+	if err != nil {
+		return Version{}, err
+	}
+	content, err := RestoreMasked(ch.Content, prev, config.SecretKeys(), config.SecretMask)
+	if err != nil {
+		return Version{}, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	if err := CheckYAML(content); err != nil {
+		return Version{}, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+
+	// 本应用的文档与本机配置合并校验后生效；其他应用的文档按相同规则校验文档中出现的键
+	own := app == c.app && env == c.env
+	if own {
+		if err := config.SetLayer(LayerName, content); err != nil {
+			return Version{}, fmt.Errorf("%w: %w", ErrInvalid, err)
+		}
+	} else if err := config.CheckDocument(content); err != nil {
+		return Version{}, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	v, err := c.store.Save(app, env, content, ch.Actor, ch.Comment)
+	if err != nil {
+		if own && !errors.Is(err, ErrNoChange) {
+			// 落盘失败时恢复之前生效的配置
+			_ = config.SetLayer(LayerName, prev)
+		}
+		return v, err
+	}
+
+	c.notify(app, env)
+
+	keys, _ := ChangedKeys(prev, content)
+	err = c.store.AppendAudit(AuditEntry{
+		Time:    time.Now(),
+		Actor:   ch.Actor,
+		IP:      ch.IP,
+		Action:  action,
+		App:     app,
+		Env:     env,
+		Version: v.Version,
+		From:    last.Version,
+		Keys:    keys,
+	})
+	if err != nil {
+		return v, fmt.Errorf("configcenter: write audit: %w", err)
+	}
+	return v, nil
+}
+
+// Wait 阻塞直到 app/env 出现比 after 新的版本（立即返回已有的新版本）或 ctx 结束；
+// ctx 结束时返回当前最新版本与 ctx.Err()
+func (c *Center) Wait(ctx context.Context, app, env string, after int) (Version, error) {
+	app, env = c.target(app, env)
+	for {
+		ch := c.changed(app, env)
+		list, err := c.store.Versions(app, env)
+		if err != nil {
+			return Version{}, err
+		}
+		var last Version
+		if len(list) > 0 {
+			last = list[len(list)-1]
+		}
+		if last.Version > after {
+			return last, nil
+		}
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return last, ctx.Err()
+		}
+	}
+}
+
+func (c *Center) changed(app, env string) <-chan struct{} {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+	key := app + "/" + env
+	ch, ok := c.watchers[key]
+	if !ok {
+		ch = make(chan struct{})
+		c.watchers[key] = ch
+	}
+	return ch
+}
+
+func (c *Center) notify(app, env string) {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+	key := app + "/" + env
+	if ch, ok := c.watchers[key]; ok {
+		close(ch)
+		delete(c.watchers, key)
+	}
+}
+
+// Raw 返回指定版本（version<=0 为最新版本）未脱敏的内容，供远程配置客户端拉取；还没有版本时返回 ErrNotFound
+func (c *Center) Raw(app, env string, version int) (Version, []byte, error) {
+	app, env = c.target(app, env)
+	if version <= 0 {
+		v, data, err := c.store.Latest(app, env)
+		if err == nil && v.Version == 0 {
+			err = ErrNotFound
+		}
+		return v, data, err
+	}
+	v, err := c.version(app, env, version)
+	if err != nil {
+		return Version{}, nil, err
+	}
+	data, err := c.store.Get(app, env, version)
+	return v, data, err
+}
+
+// Document 返回指定版本（version<=0 为最新版本）脱敏后的内容
+func (c *Center) Document(app, env string, version int) (Version, []byte, error) {
+	app, env = c.target(app, env)
+	var (
+		v    Version
+		data []byte
+		err  error
+	)
+	if version <= 0 {
+		v, data, err = c.store.Latest(app, env)
+	} else {
+		v, err = c.version(app, env, version)
+		if err == nil {
+			data, err = c.store.Get(app, env, version)
+		}
+	}
+	if err != nil {
+		return Version{}, nil, err
+	}
+	data, err = c.Mask(data)
+	return v, data, err
+}
+
+// Diff 比较两个版本脱敏后的内容，from<=0 表示空文档，to<=0 表示最新版本
+func (c *Center) Diff(app, env string, from, to int) ([]DiffLine, error) {
+	var a []byte
+	if from > 0 {
+		var err error
+		if _, a, err = c.Document(app, env, from); err != nil {
+			return nil, err
+		}
+	}
+	_, b, err := c.Document(app, env, to)
+	if err != nil {
+		return nil, err
+	}
+	return Diff(a, b), nil
+}
+
+// Mask 按 config.SecretKeys 脱敏文档
+func (c *Center) Mask(data []byte) ([]byte, error) {
+	return MaskDocument(data, config.SecretKeys(), config.SecretMask)
+}
+
+func (c *Center) version(app, env string, version int) (Version, error) {
+	list, err := c.store.Versions(app, env)
+	if err != nil {
+		return Version{}, err
+	}
+	for _, v := range list {
+		if v.Version == version {
+			return v, nil
+		}
+	}
+	return Version{}, ErrNotFound
+}
+
+func (c *Center) target(app, env string) (string, string) {
+	if app == "" {
+		app = c.app
+	}
+	if env == "" {
+		env = c.env
+	}
+	return app, env
+}
@@ -0,0 +1,230 @@
+package configcenter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+)
+
+// RemoteLayerName 远程配置文档在 config 中的配置层名
+const RemoteLayerName = "remote"
+
+// VersionHeader 远程文档接口返回版本号的响应头
+const VersionHeader = "X-Config-Version"
+
+// ClientOptions 远程配置客户端参数
+type ClientOptions struct {
+	Addr       string        // 配置中心地址，如 http://10.0.0.2:9090
+	App        string        // 文档的 app/env
+	Env        string        //
+	Token      string        // 配置中心的 config.center.token
+	Interval   time.Duration // 出错后的重试间隔；LongPoll 为 0 时为轮询间隔，默认 30s
+	LongPoll   time.Duration // 长轮询等待时间，0 表示普通轮询
+	CacheDir   string        // 缓存目录，为空时不缓存
+	HTTPClient *http.Client
+	// OnError 后台拉取或应用失败时回调（默认忽略），失败时保持当前配置
+	OnError func(err error)
+}
+
+// Client 从配置中心拉取本应用的文档并作为配置层（RemoteLayerName）生效
+type Client struct {
+	opt     ClientOptions
+	version atomic.Int64
+}
+
+// cacheEntry 磁盘缓存：最近一次校验通过的文档
+type cacheEntry struct {
+	Version int    `json:"version"`
+	Content string `json:"content"`
+}
+
+// NewClient 创建客户端，需先调用 Load 再 Run
+func NewClient(opt ClientOptions) *Client {
+	if opt.Interval <= 0 {
+		opt.Interval = 30 * time.Second
+	}
+	if opt.HTTPClient == nil {
+		opt.HTTPClient = &http.Client{Timeout: opt.LongPoll + 10*time.Second}
+	}
+	return &Client{opt: opt}
+}
+
+// Version 当前生效的远程文档版本，0 表示没有
+func (c *Client) Version() int {
+	return int(c.version.Load())
+}
+
+// Load 启动时拉取并应用文档；配置中心不可用或文档校验不通过时使用磁盘缓存（返回 ErrOffline），
+// 两者都失败时返回错误（本地配置保持不变）
+func (c *Client) Load(ctx context.Context) error {
+	v, data, err := c.fetch(ctx, 0, 0)
+	if err == nil {
+		if err = c.apply(v, data); err == nil {
+			return nil
+		}
+	}
+	cached, cerr := c.readCache()
+	if cerr != nil {
+		return fmt.Errorf("configcenter: fetch %s: %w (no usable cache: %v)", c.opt.Addr, err, cerr)
+	}
+	if aerr := config.SetLayer(RemoteLayerName, []byte(cached.Content)); aerr != nil {
+		return fmt.Errorf("configcenter: fetch %s: %w (cache rejected: %v)", c.opt.Addr, err, aerr)
+	}
+	c.version.Store(int64(cached.Version))
+	return fmt.Errorf("%w: using cached v%d: %w", ErrOffline, cached.Version, err)
+}
+
+// ErrOffline Load 使用了磁盘缓存（缓存的配置已生效，但配置中心不可用或最新文档未通过校验）
+var ErrOffline = errors.New("configcenter: remote document unavailable")
+
+// Run 持续拉取新版本直到 ctx 结束：长轮询时配置中心有新版本立即返回，否则按 Interval 轮询
+func (c *Client) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		v, data, err := c.fetch(ctx, c.Version(), c.opt.LongPoll)
+		switch {
+		case err == nil:
+			if err := c.apply(v, data); err != nil {
+				c.reportError(err)
+				// 被拒绝的版本不会自己变好，等下一个版本
+				c.version.Store(int64(v))
+				break
+			}
+			if c.opt.LongPoll > 0 {
+				continue
+			}
+		case errors.Is(err, errNotModified):
+			if c.opt.LongPoll > 0 {
+				continue
+			}
+		case ctx.Err() == nil:
+			c.reportError(err)
+		}
+		c.sleep(ctx, c.opt.Interval)
+	}
+}
+
+var errNotModified = errors.New("not modified")
+
+// fetch 拉取比 after 新的版本，等待 wait 后仍没有新版本时返回 errNotModified；文档不存在视为版本 0 的空文档
+func (c *Client) fetch(ctx context.Context, after int, wait time.Duration) (int, []byte, error) {
+	u := fmt.Sprintf("%s/config/remote/%s/%s", strings.TrimRight(c.opt.Addr, "/"),
+		url.PathEscape(c.opt.App), url.PathEscape(c.opt.Env))
+	q := url.Values{}
+	if after > 0 {
+		q.Set("version", strconv.Itoa(after))
+	}
+	if wait > 0 {
+		q.Set("wait", wait.String())
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	if c.opt.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.opt.Token)
+	}
+	resp, err := c.opt.HTTPClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+		if err != nil {
+			return 0, nil, err
+		}
+		v, err := strconv.Atoi(resp.Header.Get(VersionHeader))
+		if err != nil {
+			return 0, nil, fmt.Errorf("configcenter: missing %s header", VersionHeader)
+		}
+		return v, data, nil
+	case http.StatusNotModified:
+		return 0, nil, errNotModified
+	case http.StatusNotFound:
+		return 0, nil, nil
+	default:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return 0, nil, fmt.Errorf("configcenter: %s: %s %s", u, resp.Status, strings.TrimSpace(string(msg)))
+	}
+}
+
+// apply 校验并应用文档，成功后写入缓存
+func (c *Client) apply(version int, data []byte) error {
+	if err := config.SetLayer(RemoteLayerName, data); err != nil {
+		return fmt.Errorf("configcenter: remote v%d rejected: %w", version, err)
+	}
+	c.version.Store(int64(version))
+	if err := c.writeCache(cacheEntry{Version: version, Content: string(data)}); err != nil {
+		c.reportError(fmt.Errorf("configcenter: write cache: %w", err))
+	}
+	return nil
+}
+
+func (c *Client) cacheFile() string {
+	if c.opt.CacheDir == "" {
+		return ""
+	}
+	return filepath.Join(c.opt.CacheDir, c.opt.App+"."+c.opt.Env+".json")
+}
+
+func (c *Client) readCache() (cacheEntry, error) {
+	var e cacheEntry
+	file := c.cacheFile()
+	if file == "" {
+		return e, errors.New("cache disabled")
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return e, err
+	}
+	err = json.Unmarshal(data, &e)
+	return e, err
+}
+
+func (c *Client) writeCache(e cacheEntry) error {
+	file := c.cacheFile()
+	if file == "" {
+		return nil
+	}
+	if err := os.MkdirAll(c.opt.CacheDir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	// 文档可能包含密钥原文，writeFileAtomic 创建的文件只有当前用户可读
+	return writeFileAtomic(file, data)
+}
+
+func (c *Client) reportError(err error) {
+	if c.opt.OnError != nil {
+		c.opt.OnError(err)
+	}
+}
+
+func (c *Client) sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
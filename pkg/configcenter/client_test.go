@@ -0,0 +1,92 @@
+package configcenter_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nomoyu/go-gin-framework/nomoyu/nomoyutest"
+	"github.com/nomoyu/go-gin-framework/pkg/config"
+	"github.com/nomoyu/go-gin-framework/pkg/configcenter"
+)
+
+func TestClient(t *testing.T) {
+	srv := nomoyutest.New(t, nomoyutest.WithConfig("app: {name: srv, env: test}\nconfig: {center: {enabled: true, dir: "+t.TempDir()+", token: tok, admin: {username: root, password: pw}}}\n"))
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	req := nomoyutest.NewRequest(t, "GET", "/config/csrf", nil)
+	req.SetBasicAuth("root", "pw")
+	var csrf struct{ Token string }
+	nomoyutest.AssertSuccess(t, srv.Do(req), &csrf)
+	save := func(doc string) {
+		t.Helper()
+		req := nomoyutest.NewRequest(t, "POST", "/config/save", map[string]any{"app": "cli", "env": "test", "config": doc},
+			nomoyutest.WithHeader("X-CSRF-Token", csrf.Token))
+		req.SetBasicAuth("root", "pw")
+		nomoyutest.AssertSuccess(t, srv.Do(req), nil)
+	}
+	waitFor := func(cond func() bool) bool {
+		for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if cond() {
+				return true
+			}
+		}
+		return false
+	}
+
+	bad := configcenter.NewClient(configcenter.ClientOptions{Addr: ts.URL, App: "cli", Env: "test", Token: "bad"})
+	if err := bad.Load(context.Background()); err == nil {
+		t.Fatal("wrong token: expected error")
+	}
+
+	// 尚无文档时为空配置、版本 0
+	cache := t.TempDir()
+	errs := make(chan error, 8)
+	c := configcenter.NewClient(configcenter.ClientOptions{Addr: ts.URL, App: "cli", Env: "test", Token: "tok",
+		LongPoll: time.Second, CacheDir: cache, OnError: func(err error) { errs <- err }})
+	if err := c.Load(context.Background()); err != nil || c.Version() != 0 {
+		t.Fatalf("load: %v, version %d", err, c.Version())
+	}
+
+	save("log:\n  level: warn\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+	if !waitFor(func() bool { return config.Current().Log.Level == "warn" && c.Version() == 1 }) {
+		t.Fatalf("long poll: level %s, version %d", config.Current().Log.Level, c.Version())
+	}
+	save("log:\n  level: debug\n")
+	if !waitFor(func() bool { return config.Current().Log.Level == "debug" && c.Version() == 2 }) {
+		t.Fatalf("long poll: level %s, version %d", config.Current().Log.Level, c.Version())
+	}
+
+	// 文档本身合法、与本地配置合并后不合法：保留当前配置并报告错误
+	save("cors:\n  enabled: true\n")
+	select {
+	case err := <-errs:
+		if config.Current().Log.Level != "debug" || config.Current().CORS.Enabled {
+			t.Fatalf("invalid document applied: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("invalid document: OnError not called")
+	}
+	cancel()
+
+	// 配置中心不可用时从磁盘缓存启动
+	config.SetLayer(configcenter.RemoteLayerName, nil)
+	offline := configcenter.NewClient(configcenter.ClientOptions{Addr: "http://127.0.0.1:1", App: "cli", Env: "test", CacheDir: cache})
+	if err := offline.Load(context.Background()); !errors.Is(err, configcenter.ErrOffline) ||
+		config.Current().Log.Level != "debug" || offline.Version() != 2 {
+		t.Fatalf("offline: %v, level %s, version %d", err, config.Current().Log.Level, offline.Version())
+	}
+
+	// config.remote 配置后 Build 时加载远程配置（两个应用共用全局配置，配置中心的 token 需同时保留）
+	save("log:\n  level: debug\nserver:\n  port: 4000\n")
+	nomoyutest.New(t, nomoyutest.WithConfig("app: {name: cli, env: test}\nconfig: {center: {token: tok}, remote: {addr: "+ts.URL+", token: tok, cache_dir: "+t.TempDir()+", long_poll: 1s}}\n"))
+	if config.Current().Log.Level != "debug" || config.Current().Server.Port != 4000 {
+		t.Fatalf("nomoyu wiring: level %s, port %d", config.Current().Log.Level, config.Current().Server.Port)
+	}
+}
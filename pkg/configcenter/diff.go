@@ -0,0 +1,149 @@
+package configcenter
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DiffLine 逐行差异中的一行：Op 为 " "（相同）、"-"（删除）、"+"（新增）
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// maxDiffCells LCS 表的上限，超过时退化为整体替换
+const maxDiffCells = 4_000_000
+
+// Diff 按行比较两个文档（最长公共子序列）
+func Diff(from, to []byte) []DiffLine {
+	a, b := splitLines(from), splitLines(to)
+	if len(a)*len(b) > maxDiffCells {
+		out := make([]DiffLine, 0, len(a)+len(b))
+		for _, l := range a {
+			out = append(out, DiffLine{Op: "-", Text: l})
+		}
+		for _, l := range b {
+			out = append(out, DiffLine{Op: "+", Text: l})
+		}
+		return out
+	}
+
+	// lcs[i][j] = a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []DiffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, DiffLine{Op: " ", Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, DiffLine{Op: "-", Text: a[i]})
+			i++
+		default:
+			out = append(out, DiffLine{Op: "+", Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		out = append(out, DiffLine{Op: "-", Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		out = append(out, DiffLine{Op: "+", Text: b[j]})
+	}
+	return out
+}
+
+func splitLines(data []byte) []string {
+	s := strings.TrimRight(string(data), "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// ChangedKeys 返回两个 YAML 文档之间值发生变化（新增、删除、修改）的键，如 server.port；列表整体视为一个值
+func ChangedKeys(from, to []byte) ([]string, error) {
+	a, err := flatten(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := flatten(to)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for k, av := range a {
+		if bv, ok := b[k]; !ok || !reflect.DeepEqual(av, bv) {
+			keys = append(keys, k)
+		}
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func flatten(data []byte) (map[string]any, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	out := map[string]any{}
+	if len(doc) == 0 {
+		return out, nil
+	}
+	var walk func(prefix string, v any)
+	walk = func(prefix string, v any) {
+		m, ok := v.(map[string]any)
+		if !ok || len(m) == 0 {
+			out[prefix] = v
+			return
+		}
+		for k, child := range m {
+			key := strings.ToLower(k)
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			walk(key, child)
+		}
+	}
+	walk("", doc)
+	return out, nil
+}
+
+// CheckYAML 文档必须为空或 YAML 映射
+func CheckYAML(data []byte) error {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("invalid YAML: %w", err)
+	}
+	if doc == nil {
+		return nil
+	}
+	if _, ok := doc.(map[string]any); !ok {
+		return fmt.Errorf("invalid YAML: top level must be a mapping")
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package configcenter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MaskDocument 把文档中 secretKeys（如 database.password）对应的明文值替换为 mask，保留注释与格式；
+// ${env:}/${file:} 引用与 enc: 密文本身不含密钥，原样显示
+func MaskDocument(data []byte, secretKeys []string, mask string) ([]byte, error) {
+	root, err := parseNode(data)
+	if err != nil || root == nil {
+		return data, err
+	}
+	changed := false
+	for _, key := range secretKeys {
+		if n := lookup(root, key); n != nil && isPlainSecret(n) {
+			n.Value, n.Style, n.Tag = mask, 0, "!!str"
+			changed = true
+		}
+	}
+	if !changed {
+		return data, nil
+	}
+	return encodeNode(root)
+}
+
+// RestoreMasked 把新文档中仍为 mask 的密钥值还原为旧文档中的值（编辑页面提交时密钥未被修改）
+func RestoreMasked(data, previous []byte, secretKeys []string, mask string) ([]byte, error) {
+	root, err := parseNode(data)
+	if err != nil || root == nil {
+		return data, err
+	}
+	prev, err := parseNode(previous)
+	if err != nil {
+		return nil, err
+	}
+	changed := false
+	for _, key := range secretKeys {
+		n := lookup(root, key)
+		if n == nil || n.Kind != yaml.ScalarNode || n.Value != mask {
+			continue
+		}
+		var old *yaml.Node
+		if prev != nil {
+			old = lookup(prev, key)
+		}
+		if old == nil || old.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("%s is %s but has no previous value", key, mask)
+		}
+		n.Value, n.Style, n.Tag = old.Value, old.Style, old.Tag
+		changed = true
+	}
+	if !changed {
+		return data, nil
+	}
+	return encodeNode(root)
+}
+
+func isPlainSecret(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && n.Value != "" &&
+		!strings.HasPrefix(n.Value, "${") && !strings.HasPrefix(n.Value, "enc:")
+}
+
+// parseNode 返回顶层映射节点，空文档返回 nil
+func parseNode(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, nil
+	}
+	return &doc, nil
+}
+
+// lookup 按点分路径（大小写不敏感）查找值节点
+func lookup(doc *yaml.Node, key string) *yaml.Node {
+	n := doc.Content[0]
+	for _, part := range strings.Split(key, ".") {
+		if n.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if strings.EqualFold(n.Content[i].Value, part) {
+				next = n.Content[i+1]
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		n = next
+	}
+	return n
+}
+
+func encodeNode(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
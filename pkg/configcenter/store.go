@@ -0,0 +1,235 @@
+// Package configcenter 配置中心：按 app/env 保存 YAML 配置文档的版本历史、差异与审计记录
+package configcenter
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotFound  = errors.New("configcenter: version not found")
+	ErrNoChange  = errors.New("configcenter: content unchanged")
+	ErrBadTarget = errors.New("configcenter: invalid app or env name")
+)
+
+// Version 文档的一个版本（内容单独保存）
+type Version struct {
+	Version  int       `json:"version"`
+	Time     time.Time `json:"time"`
+	Author   string    `json:"author"`
+	Comment  string    `json:"comment,omitempty"`
+	Checksum string    `json:"checksum"` // 内容 sha256 的前 12 位
+}
+
+// AuditEntry 一次修改的审计记录：只记录变化的键，不记录值（避免泄露密钥）
+type AuditEntry struct {
+	Time    time.Time `json:"time"`
+	Actor   string    `json:"actor"`
+	IP      string    `json:"ip,omitempty"`
+	Action  string    `json:"action"` // save / rollback
+	App     string    `json:"app"`
+	Env     string    `json:"env"`
+	Version int       `json:"version"`
+	From    int       `json:"from"` // 修改前的版本，0 表示首个版本
+	Keys    []string  `json:"keys"` // 变化的配置键，如 server.port
+}
+
+// Store 基于目录的存储：
+//
+//	<dir>/<app>/<env>/v<N>.yaml    各版本内容
+//	<dir>/<app>/<env>/history.jsonl 版本元数据
+//	<dir>/audit.jsonl               审计记录
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// Open 打开（必要时创建）存储目录
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("configcenter: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+func (s *Store) docDir(app, env string) (string, error) {
+	if !namePattern.MatchString(app) || !namePattern.MatchString(env) {
+		return "", ErrBadTarget
+	}
+	return filepath.Join(s.dir, app, env), nil
+}
+
+// Versions 返回全部版本，按版本号升序
+func (s *Store) Versions(app, env string) ([]Version, error) {
+	dir, err := s.docDir(app, env)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return readJSONLines[Version](filepath.Join(dir, "history.jsonl"))
+}
+
+// Latest 返回最新版本及内容，还没有版本时返回零值 Version 与 nil
+func (s *Store) Latest(app, env string) (Version, []byte, error) {
+	list, err := s.Versions(app, env)
+	if err != nil || len(list) == 0 {
+		return Version{}, nil, err
+	}
+	last := list[len(list)-1]
+	data, err := s.Get(app, env, last.Version)
+	return last, data, err
+}
+
+// Get 返回指定版本的内容
+func (s *Store) Get(app, env string, version int) ([]byte, error) {
+	dir, err := s.docDir(app, env)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("v%d.yaml", version)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// Save 保存新版本，内容与最新版本相同时返回 ErrNoChange
+func (s *Store) Save(app, env string, data []byte, author, comment string) (Version, error) {
+	dir, err := s.docDir(app, env)
+	if err != nil {
+		return Version{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := readJSONLines[Version](filepath.Join(dir, "history.jsonl"))
+	if err != nil {
+		return Version{}, err
+	}
+	sum := checksum(data)
+	next := 1
+	if len(list) > 0 {
+		last := list[len(list)-1]
+		if last.Checksum == sum {
+			return last, ErrNoChange
+		}
+		next = last.Version + 1
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return Version{}, err
+	}
+	if err := writeFileAtomic(filepath.Join(dir, fmt.Sprintf("v%d.yaml", next)), data); err != nil {
+		return Version{}, err
+	}
+	v := Version{Version: next, Time: time.Now(), Author: author, Comment: comment, Checksum: sum}
+	if err := appendJSONLine(filepath.Join(dir, "history.jsonl"), v); err != nil {
+		return Version{}, err
+	}
+	return v, nil
+}
+
+// AppendAudit 追加审计记录
+func (s *Store) AppendAudit(e AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return appendJSONLine(filepath.Join(s.dir, "audit.jsonl"), e)
+}
+
+// Audit 返回最近 limit 条审计记录（新的在前），app/env 为空时不过滤；limit<=0 表示全部
+func (s *Store) Audit(app, env string, limit int) ([]AuditEntry, error) {
+	s.mu.Lock()
+	all, err := readJSONLines[AuditEntry](filepath.Join(s.dir, "audit.jsonl"))
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	var out []AuditEntry
+	for i := len(all) - 1; i >= 0; i-- {
+		e := all[i]
+		if (app != "" && e.App != app) || (env != "" && e.Env != env) {
+			continue
+		}
+		out = append(out, e)
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+func readJSONLines[T any](file string) ([]T, error) {
+	f, err := os.Open(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []T
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var item T
+		if err := json.Unmarshal(sc.Bytes(), &item); err != nil {
+			return nil, fmt.Errorf("configcenter: corrupt %s: %w", file, err)
+		}
+		out = append(out, item)
+	}
+	return out, sc.Err()
+}
+
+func appendJSONLine(file string, v any) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeFileAtomic 先写临时文件再改名，避免进程中断留下半个文件
+func writeFileAtomic(file string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
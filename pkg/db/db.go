@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
@@ -127,3 +128,36 @@ func MustDB() *gorm.DB {
 	}
 	return inst
 }
+
+// Close 关闭底层连接池（优雅停机时调用）
+func Close() error {
+	if inst == nil {
+		return nil
+	}
+	sqlDB, err := inst.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// Reset 关闭连接并清空单例，之后可以重新 Init（主要用于测试）
+func Reset() error {
+	err := Close()
+	inst = nil
+	initErr = nil
+	once = sync.Once{}
+	return err
+}
+
+// Ping 探活（健康检查使用）
+func Ping(ctx context.Context) error {
+	if inst == nil {
+		return errors.New("db: 尚未初始化")
+	}
+	sqlDB, err := inst.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
@@ -1,25 +1,78 @@
 package errorcode
 
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
 type ErrorCode struct {
 	Code int
 	Msg  string
 }
 
+var (
+	mu       sync.RWMutex
+	registry = map[int]ErrorCode{}
+)
+
+// New 定义并登记一个错误码；登记过的错误码会出现在 OpenAPI 文档的错误码表中
+func New(code int, msg string) ErrorCode {
+	ec := ErrorCode{Code: code, Msg: msg}
+	mu.Lock()
+	registry[code] = ec
+	mu.Unlock()
+	return ec
+}
+
+// All 返回所有已登记的错误码（按 Code 升序）
+func All() []ErrorCode {
+	mu.RLock()
+	list := make([]ErrorCode, 0, len(registry))
+	for _, ec := range registry {
+		list = append(list, ec)
+	}
+	mu.RUnlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+// Error 实现 error 接口，业务代码可直接 return errorcode.UserNotFound
+func (e ErrorCode) Error() string {
+	return e.Msg
+}
+
+// WithMsg 保留错误码、替换提示信息
+func (e ErrorCode) WithMsg(msg string) ErrorCode {
+	e.Msg = msg
+	return e
+}
+
+// From 从错误链中取出 ErrorCode（支持 fmt.Errorf("...: %w", errorcode.X) 包装）
+func From(err error) (ErrorCode, bool) {
+	var ec ErrorCode
+	if errors.As(err, &ec) {
+		return ec, true
+	}
+	return ec, false
+}
+
 // 定义一个常见错误码集合
 var (
-	Success     = ErrorCode{Code: 0, Msg: "success"}
-	ServerError = ErrorCode{Code: 500, Msg: "服务器内部错误"}
+	Success     = New(0, "success")
+	ServerError = New(500, "服务器内部错误")
 
 	// 通用参数类
-	InvalidParams = ErrorCode{Code: 1000, Msg: "请求参数不合法"}
-	Unauthorized  = ErrorCode{Code: 1001, Msg: "未授权或 token 无效"}
-	Forbidden     = ErrorCode{Code: 1002, Msg: "无权限访问"}
+	InvalidParams   = New(1000, "请求参数不合法")
+	Unauthorized    = New(1001, "未授权或 token 无效")
+	Forbidden       = New(1002, "无权限访问")
+	TooManyRequests = New(1003, "请求过于频繁，请稍后再试")
 
 	// 用户类错误码
-	UserNotFound = ErrorCode{Code: 2001, Msg: "用户不存在"}
-	UserExists   = ErrorCode{Code: 2002, Msg: "用户已存在"}
-	LoginFailed  = ErrorCode{Code: 2003, Msg: "用户名或密码错误"}
+	UserNotFound = New(2001, "用户不存在")
+	UserExists   = New(2002, "用户已存在")
+	LoginFailed  = New(2003, "用户名或密码错误")
 
 	// 业务类错误码示例
-	OrderNotFound = ErrorCode{Code: 3001, Msg: "订单不存在"}
+	OrderNotFound = New(3001, "订单不存在")
 )
@@ -0,0 +1,124 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nomoyu/go-gin-framework/pkg/db"
+	"github.com/nomoyu/go-gin-framework/pkg/redisx"
+	"github.com/nomoyu/go-gin-framework/pkg/scheduler"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Checker 健康检查项，返回 nil 表示组件正常
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type checkerFunc struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func (c checkerFunc) Name() string                    { return c.name }
+func (c checkerFunc) Check(ctx context.Context) error { return c.fn(ctx) }
+
+// New 用函数快速构造一个检查项
+func New(name string, fn func(ctx context.Context) error) Checker {
+	return checkerFunc{name: name, fn: fn}
+}
+
+// ComponentReport 单个组件的检查结果
+type ComponentReport struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Latency string `json:"latency"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Report 汇总结果：任一组件 down 则整体 down
+type Report struct {
+	Status     string            `json:"status"`
+	Components []ComponentReport `json:"components,omitempty"`
+}
+
+// Up 是否全部正常
+func (r Report) Up() bool {
+	return r.Status == StatusUp
+}
+
+// Run 并发执行所有检查项，每项受 timeout 限制（<=0 时不额外限制）
+func Run(ctx context.Context, timeout time.Duration, checkers ...Checker) Report {
+	report := Report{Status: StatusUp, Components: make([]ComponentReport, len(checkers))}
+
+	var wg sync.WaitGroup
+	for i, c := range checkers {
+		wg.Add(1)
+		go func(i int, c Checker) {
+			defer wg.Done()
+			report.Components[i] = runOne(ctx, timeout, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for _, c := range report.Components {
+		if c.Status != StatusUp {
+			report.Status = StatusDown
+			break
+		}
+	}
+	return report
+}
+
+func runOne(ctx context.Context, timeout time.Duration, c Checker) (res ComponentReport) {
+	res = ComponentReport{Name: c.Name(), Status: StatusUp}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	defer func() {
+		if rec := recover(); rec != nil {
+			res.Status = StatusDown
+			res.Error = fmt.Sprintf("panic: %v", rec)
+		}
+		res.Latency = time.Since(start).String()
+	}()
+
+	if err := c.Check(ctx); err != nil {
+		res.Status = StatusDown
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// DB 检查 pkg/db 连接（ping）
+func DB() Checker {
+	return New("db", db.Ping)
+}
+
+// Redis 检查 pkg/redisx 连接（ping）
+func Redis() Checker {
+	return New("redis", redisx.Ping)
+}
+
+// Scheduler 检查调度器是否处于运行状态
+func Scheduler(schedulers ...*scheduler.Scheduler) Checker {
+	return New("scheduler", func(ctx context.Context) error {
+		for i, s := range schedulers {
+			if s != nil && !s.Running() {
+				return fmt.Errorf("scheduler #%d is not running", i)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,66 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nomoyu/go-gin-framework/pkg/scheduler"
+)
+
+func TestRun(t *testing.T) {
+	report := Run(context.Background(), 50*time.Millisecond,
+		New("ok", func(context.Context) error { return nil }),
+		New("mq", func(context.Context) error { return errors.New("connection refused") }),
+		New("slow", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}),
+		New("panic", func(context.Context) error { panic("boom") }),
+	)
+	if report.Up() || report.Status != StatusDown {
+		t.Fatalf("status = %s, want down", report.Status)
+	}
+
+	want := []ComponentReport{
+		{Name: "ok", Status: StatusUp},
+		{Name: "mq", Status: StatusDown, Error: "connection refused"},
+		{Name: "slow", Status: StatusDown, Error: context.DeadlineExceeded.Error()},
+		{Name: "panic", Status: StatusDown, Error: "panic: boom"},
+	}
+	for i, c := range report.Components {
+		if c.Name != want[i].Name || c.Status != want[i].Status || c.Error != want[i].Error {
+			t.Errorf("component %d = %+v, want %+v", i, c, want[i])
+		}
+		if _, err := time.ParseDuration(c.Latency); err != nil {
+			t.Errorf("component %s latency %q: %v", c.Name, c.Latency, err)
+		}
+	}
+	if d, _ := time.ParseDuration(report.Components[2].Latency); d < 50*time.Millisecond {
+		t.Errorf("slow checker latency %s, want >= timeout", d)
+	}
+}
+
+func TestRunAllUp(t *testing.T) {
+	report := Run(context.Background(), 0, New("ok", func(context.Context) error { return nil }))
+	if !report.Up() || len(report.Components) != 1 || report.Components[0].Status != StatusUp {
+		t.Fatalf("report = %+v", report)
+	}
+	if report := Run(context.Background(), 0); !report.Up() {
+		t.Fatalf("no checkers: %+v", report)
+	}
+}
+
+func TestSchedulerChecker(t *testing.T) {
+	s := scheduler.New()
+	if err := Scheduler(s).Check(context.Background()); err == nil || !strings.Contains(err.Error(), "not running") {
+		t.Fatalf("stopped scheduler: %v", err)
+	}
+	s.Start()
+	defer s.Stop(context.Background())
+	if err := Scheduler(s, nil).Check(context.Background()); err != nil {
+		t.Fatalf("running scheduler: %v", err)
+	}
+}
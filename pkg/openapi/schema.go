@@ -0,0 +1,346 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	pkgPathRe      = regexp.MustCompile(`[\w.\-]+/`)
+	invalidNameRe  = regexp.MustCompile(`[^A-Za-z0-9._\-]+`)
+)
+
+// Generator 把 Go 类型转换为 Schema：具名结构体登记到 components/schemas 并以 $ref 引用
+type Generator struct {
+	doc   *Document
+	names map[reflect.Type]string
+}
+
+func NewGenerator(doc *Document) *Generator {
+	return &Generator{doc: doc, names: map[reflect.Type]string{}}
+}
+
+// Schema 返回类型 t 的 Schema
+func (g *Generator) Schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer"}
+	case reflect.Int32, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.Schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.Schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t, nil)
+		}
+		return g.ref(t)
+	default: // interface 等
+		return &Schema{}
+	}
+}
+
+// ref 登记具名结构体；先放占位再生成属性，支持自引用类型
+func (g *Generator) ref(t reflect.Type) *Schema {
+	if name, ok := g.names[t]; ok {
+		return Ref(name)
+	}
+	name := g.schemaName(t)
+	g.names[t] = name
+	g.doc.Components.Schemas[name] = &Schema{}
+	g.doc.Components.Schemas[name] = g.object(t, nil)
+	return Ref(name)
+}
+
+// schemaName 类型名去掉包路径（泛型参数同样处理），重名时加包名前缀
+func (g *Generator) schemaName(t reflect.Type) string {
+	clean := func(s string) string {
+		s = pkgPathRe.ReplaceAllString(s, "")
+		s = strings.NewReplacer("[", "_", "]", "", ",", "_", "*", "").Replace(s)
+		return invalidNameRe.ReplaceAllString(s, "_")
+	}
+	name := clean(t.Name())
+	if _, used := g.doc.Components.Schemas[name]; !used {
+		return name
+	}
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	name = clean(pkg + "." + t.Name())
+	candidate := name
+	for i := 2; g.doc.Components.Schemas[candidate] != nil; i++ {
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+	return candidate
+}
+
+// object 生成结构体的对象 Schema；include 非 nil 时只保留返回 true 的字段
+func (g *Generator) object(t reflect.Type, include func(f reflect.StructField) bool) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.fields(t, include, s)
+	if len(s.Properties) == 0 {
+		s.Properties = nil
+	}
+	return s
+}
+
+func (g *Generator) fields(t reflect.Type, include func(f reflect.StructField) bool, s *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, skip := jsonName(f)
+		if skip {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		// 未指定 json 名的嵌入结构体：字段提升到外层
+		if f.Anonymous && ft.Kind() == reflect.Struct && f.Tag.Get("json") == "" && ft != timeType {
+			g.fields(ft, include, s)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if include != nil && !include(f) {
+			continue
+		}
+
+		prop := g.Schema(f.Type)
+		if strings.Contains(opts, "string") {
+			prop = &Schema{Type: "string"}
+		}
+		applyBinding(prop, ft, f.Tag.Get("binding"))
+		s.Properties[name] = prop
+		if isRequired(f) {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func jsonName(f reflect.StructField) (name, opts string, skip bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", "", true
+	}
+	name, opts, _ = strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	return name, opts, false
+}
+
+func isRequired(f reflect.StructField) bool {
+	for _, rule := range strings.Split(f.Tag.Get("binding"), ",") {
+		if rule == "required" {
+			return true
+		}
+		if rule == "dive" {
+			break
+		}
+	}
+	return false
+}
+
+// Parameters 从结构体的 uri / form / header 标签生成 path / query / header 参数
+func (g *Generator) Parameters(t reflect.Type) []Parameter {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var params []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			params = append(params, g.Parameters(f.Type)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		for _, src := range []struct{ tag, in string }{{"uri", "path"}, {"form", "query"}, {"header", "header"}} {
+			name, _, _ := strings.Cut(f.Tag.Get(src.tag), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			schema := g.Schema(f.Type)
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			applyBinding(schema, ft, f.Tag.Get("binding"))
+			params = append(params, Parameter{
+				Name:     name,
+				In:       src.in,
+				Required: src.in == "path" || isRequired(f),
+				Schema:   schema,
+			})
+		}
+	}
+	return params
+}
+
+// Body 请求体 Schema：排除只来自 uri / form / header 的字段；没有请求体字段时返回 nil
+func (g *Generator) Body(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return g.Schema(t)
+	}
+	all := true
+	s := g.object(t, func(f reflect.StructField) bool {
+		if isBodyField(f) {
+			return true
+		}
+		all = false
+		return false
+	})
+	if s.Properties == nil {
+		return nil
+	}
+	if all && t.Name() != "" {
+		return g.Schema(t)
+	}
+	return s
+}
+
+func isBodyField(f reflect.StructField) bool {
+	if _, ok := f.Tag.Lookup("json"); ok {
+		return true
+	}
+	for _, tag := range []string{"uri", "form", "header"} {
+		if _, ok := f.Tag.Lookup(tag); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// applyBinding 把常见的 validator 规则映射为 Schema 约束（dive 之后的规则作用于元素，忽略）
+func applyBinding(s *Schema, t reflect.Type, tag string) {
+	if tag == "" || s.Ref != "" {
+		return
+	}
+	for _, rule := range strings.Split(tag, ",") {
+		key, val, _ := strings.Cut(rule, "=")
+		if key == "dive" {
+			return
+		}
+		switch key {
+		case "min", "max", "len":
+			n, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				continue
+			}
+			applyRange(s, t.Kind(), key, n)
+		case "gt", "gte", "lt", "lte":
+			n, err := strconv.ParseFloat(val, 64)
+			if err != nil || !isNumber(t.Kind()) {
+				continue
+			}
+			switch key {
+			case "gt":
+				s.ExclusiveMinimum = &n
+			case "gte":
+				s.Minimum = &n
+			case "lt":
+				s.ExclusiveMaximum = &n
+			case "lte":
+				s.Maximum = &n
+			}
+		case "oneof":
+			for _, v := range strings.Fields(val) {
+				if isNumber(t.Kind()) {
+					if n, err := strconv.ParseFloat(v, 64); err == nil {
+						s.Enum = append(s.Enum, n)
+						continue
+					}
+				}
+				s.Enum = append(s.Enum, v)
+			}
+		case "email":
+			s.Format = "email"
+		case "url", "uri":
+			s.Format = "uri"
+		case "uuid", "uuid4":
+			s.Format = "uuid"
+		case "ip", "ipv4":
+			s.Format = "ipv4"
+		case "ipv6":
+			s.Format = "ipv6"
+		}
+	}
+}
+
+func applyRange(s *Schema, kind reflect.Kind, key string, n float64) {
+	i := int(n)
+	switch {
+	case kind == reflect.String:
+		if key != "max" {
+			s.MinLength = &i
+		}
+		if key != "min" {
+			s.MaxLength = &i
+		}
+	case kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map:
+		if key != "max" {
+			s.MinItems = &i
+		}
+		if key != "min" {
+			s.MaxItems = &i
+		}
+	case isNumber(kind):
+		if key != "max" {
+			s.Minimum = &n
+		}
+		if key != "min" {
+			s.Maximum = &n
+		}
+	}
+}
+
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
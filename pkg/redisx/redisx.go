@@ -77,6 +77,15 @@ func Close() error {
 	return client.Close()
 }
 
+// Ping 探活（健康检查使用）
+func Ping(ctx context.Context) error {
+	c, err := Client()
+	if err != nil {
+		return err
+	}
+	return c.Ping(ctx).Err()
+}
+
 func Client() (redis.UniversalClient, error) {
 	if client == nil {
 		return nil, ErrNotInitialized
@@ -19,6 +19,7 @@ type RequestCtx struct {
 	AuthInfo map[string]any
 	ClientIP string
 	UA       string
+	Version  string // 命中路由声明的 API 版本
 
 	Values map[string]any // 使用者自存临时键值
 }
@@ -0,0 +1,427 @@
+// Package sse Server-Sent Events：按主题推送、心跳、Last-Event-ID 断线续传，可通过 Redis 在多实例间广播
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 框架默认值
+const (
+	defaultHeartbeat    = 15 * time.Second
+	defaultReplaySize   = 100
+	defaultReplayTTL    = 5 * time.Minute
+	defaultClientBuffer = 64
+)
+
+var (
+	ErrClosed               = errors.New("sse: broker closed")
+	ErrSlowConsumer         = errors.New("sse: client buffer full")
+	ErrStreamingUnsupported = errors.New("sse: response writer does not support flushing")
+)
+
+type Options struct {
+	Heartbeat time.Duration // 心跳注释行间隔，防止代理断开空闲连接，默认 15s
+	// ReplaySize 每个主题保留的最近事件数，客户端带 Last-Event-ID 重连时补发，默认 100
+	ReplaySize int
+	// ReplayTTL 补发缓冲中事件的保留时间，默认 5m；过期后无订阅者的主题会被清理
+	ReplayTTL time.Duration
+	// ClientBuffer 每个客户端的发送队列长度，默认 64；队列满时断开连接，由客户端重连后补发
+	ClientBuffer int
+	// Retry 建议客户端的重连间隔（写入 retry: 字段），为 0 时不发送
+	Retry time.Duration
+}
+
+// Event 一条 SSE 事件；ID 为空时由 Publish 生成
+type Event struct {
+	ID    string `json:"id"`
+	Event string `json:"event,omitempty"` // 事件类型，对应前端 addEventListener(type)
+	Data  string `json:"data"`
+}
+
+// Fanout 跨实例广播：Publish 发往所有实例（含自身）；Subscribe 订阅成功后调用 ready，
+// 随后把收到的事件交给 deliver 本地投递，阻塞到 ctx 结束或连接出错
+type Fanout interface {
+	Publish(ctx context.Context, topic string, e Event) error
+	Subscribe(ctx context.Context, ready func(), deliver func(topic string, e Event)) error
+}
+
+// Handler 在开始推送前调用：校验权限、订阅主题；返回错误时不建立事件流
+type Handler func(s *Stream) error
+
+type Broker struct {
+	opt    Options
+	fanout Fanout
+
+	mu        sync.Mutex
+	topics    map[string]*topic
+	streams   map[*Stream]struct{}
+	lastSweep time.Time
+	closed    bool
+	done      chan struct{}
+
+	seq       uint64
+	startOnce sync.Once
+	startErr  error
+	cancel    context.CancelFunc
+}
+
+type topic struct {
+	subs   map[*Stream]struct{}
+	replay []storedEvent
+}
+
+type storedEvent struct {
+	Event
+	at time.Time
+}
+
+func NewBroker(opt Options) *Broker {
+	if opt.Heartbeat <= 0 {
+		opt.Heartbeat = defaultHeartbeat
+	}
+	if opt.ReplaySize <= 0 {
+		opt.ReplaySize = defaultReplaySize
+	}
+	if opt.ReplayTTL <= 0 {
+		opt.ReplayTTL = defaultReplayTTL
+	}
+	if opt.ClientBuffer <= 0 {
+		opt.ClientBuffer = defaultClientBuffer
+	}
+	return &Broker{
+		opt:     opt,
+		topics:  map[string]*topic{},
+		streams: map[*Stream]struct{}{},
+		done:    make(chan struct{}),
+	}
+}
+
+// WithFanout 设置跨实例广播（需在第一次 Publish/Serve 之前调用）
+func (b *Broker) WithFanout(f Fanout) *Broker {
+	b.fanout = f
+	return b
+}
+
+// start 第一次使用时订阅 Fanout；首次订阅同步完成，保证本实例随后 Publish 的事件能收到
+func (b *Broker) start() error {
+	b.startOnce.Do(func() {
+		if b.fanout == nil {
+			return
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		b.cancel = cancel
+		ready := make(chan error, 1)
+		go b.subscribeLoop(ctx, ready)
+		b.startErr = <-ready
+	})
+	return b.startErr
+}
+
+// subscribeLoop 订阅断开后按退避间隔重连，直到 Close
+func (b *Broker) subscribeLoop(ctx context.Context, ready chan<- error) {
+	var once sync.Once
+	backoff := 100 * time.Millisecond
+	for {
+		err := b.fanout.Subscribe(ctx, func() {
+			once.Do(func() { ready <- nil })
+			backoff = 100 * time.Millisecond
+		}, b.deliver)
+		if ctx.Err() != nil {
+			return
+		}
+		once.Do(func() { ready <- err })
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		if backoff < 5*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+// Publish 向主题发布事件；设置了 Fanout 时经由 Fanout 投递到所有实例
+func (b *Broker) Publish(ctx context.Context, topic string, e Event) error {
+	if b.isClosed() {
+		return ErrClosed
+	}
+	if e.ID == "" {
+		e.ID = b.nextID()
+	}
+	if b.fanout != nil {
+		if err := b.start(); err != nil {
+			return err
+		}
+		return b.fanout.Publish(ctx, topic, e)
+	}
+	b.deliver(topic, e)
+	return nil
+}
+
+// PublishJSON 以 JSON 作为 data 发布事件
+func (b *Broker) PublishJSON(ctx context.Context, topic, event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return b.Publish(ctx, topic, Event{Event: event, Data: string(data)})
+}
+
+// nextID 毫秒时间戳-序号，多实例发布的事件也大致有序，Last-Event-ID 不在补发缓冲中时按大小比较
+func (b *Broker) nextID() string {
+	return fmt.Sprintf("%d-%d", time.Now().UnixMilli(), atomic.AddUint64(&b.seq, 1))
+}
+
+// deliver 写入补发缓冲并投递给本地订阅者
+func (b *Broker) deliver(name string, e Event) {
+	now := time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	t := b.topicLocked(name)
+	t.replay = append(t.replay, storedEvent{Event: e, at: now})
+	if len(t.replay) > b.opt.ReplaySize {
+		t.replay = append(t.replay[:0:0], t.replay[len(t.replay)-b.opt.ReplaySize:]...)
+	}
+	for s := range t.subs {
+		s.enqueue(e)
+	}
+	b.sweepLocked(now)
+}
+
+func (b *Broker) topicLocked(name string) *topic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &topic{subs: map[*Stream]struct{}{}}
+		b.topics[name] = t
+	}
+	return t
+}
+
+// sweepLocked 定期清理过期事件与无订阅者的空主题
+func (b *Broker) sweepLocked(now time.Time) {
+	if now.Sub(b.lastSweep) < b.opt.ReplayTTL/2 {
+		return
+	}
+	b.lastSweep = now
+	expire := now.Add(-b.opt.ReplayTTL)
+	for name, t := range b.topics {
+		i := 0
+		for i < len(t.replay) && t.replay[i].at.Before(expire) {
+			i++
+		}
+		t.replay = t.replay[i:]
+		if len(t.replay) == 0 && len(t.subs) == 0 {
+			delete(b.topics, name)
+		}
+	}
+}
+
+// subscribe 订阅主题并取出 lastID 之后的补发事件（与投递在同一把锁内，不会漏发或重复）
+func (b *Broker) subscribe(s *Stream, names []string, lastID string) []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var replay []Event
+	for _, name := range names {
+		t := b.topicLocked(name)
+		t.subs[s] = struct{}{}
+		s.topics = append(s.topics, name)
+		if lastID != "" {
+			replay = append(replay, eventsAfter(t.replay, lastID)...)
+		}
+	}
+	if len(names) > 1 {
+		sort.SliceStable(replay, func(i, j int) bool { return idLess(replay[i].ID, replay[j].ID) })
+	}
+	return replay
+}
+
+func (b *Broker) unsubscribe(s *Stream) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, name := range s.topics {
+		if t, ok := b.topics[name]; ok {
+			delete(t.subs, s)
+		}
+	}
+	delete(b.streams, s)
+}
+
+// eventsAfter 补发 lastID 之后的事件；lastID 不在缓冲中时按 ID 大小比较（仅限 Publish 生成的 ID）
+func eventsAfter(events []storedEvent, lastID string) []Event {
+	start := -1
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].ID == lastID {
+			start = i + 1
+			break
+		}
+	}
+	if _, _, ok := parseID(lastID); start < 0 && !ok {
+		return nil
+	}
+	var out []Event
+	for i, e := range events {
+		if (start >= 0 && i >= start) || (start < 0 && idLess(lastID, e.ID)) {
+			out = append(out, e.Event)
+		}
+	}
+	return out
+}
+
+// idLess 比较 nextID 生成的 ID；无法解析时按字符串比较
+func idLess(a, b string) bool {
+	am, as, aok := parseID(a)
+	bm, bs, bok := parseID(b)
+	if !aok || !bok {
+		return a < b
+	}
+	if am != bm {
+		return am < bm
+	}
+	return as < bs
+}
+
+func parseID(id string) (ms, seq uint64, ok bool) {
+	l, r, found := strings.Cut(id, "-")
+	if !found {
+		return 0, 0, false
+	}
+	ms, err1 := strconv.ParseUint(l, 10, 64)
+	seq, err2 := strconv.ParseUint(r, 10, 64)
+	return ms, seq, err1 == nil && err2 == nil
+}
+
+// Count 当前连接数
+func (b *Broker) Count() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.streams)
+}
+
+func (b *Broker) isClosed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
+
+// Close 停止 Fanout 订阅并结束所有事件流（客户端会按 retry 自动重连到其他实例），可重复调用
+func (b *Broker) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	close(b.done)
+	b.mu.Unlock()
+	if b.cancel != nil {
+		b.cancel()
+	}
+}
+
+// Serve 调用 handler 后以 text/event-stream 持续推送，阻塞到客户端断开或 Broker 关闭；
+// handler 返回错误时尚未写出任何响应，由调用方处理
+func (b *Broker) Serve(w http.ResponseWriter, r *http.Request, user map[string]interface{}, handler Handler) error {
+	if b.isClosed() {
+		http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+		return ErrClosed
+	}
+	if err := b.start(); err != nil {
+		return err
+	}
+	rc := http.NewResponseController(w)
+
+	s := newStream(b, r, user)
+	if err := handler(s); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+		return ErrClosed
+	}
+	b.streams[s] = struct{}{}
+	b.mu.Unlock()
+	defer b.unsubscribe(s)
+	replay := b.subscribe(s, s.pending, s.LastEventID)
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no") // 关闭 nginx 缓冲
+	w.WriteHeader(http.StatusOK)
+	// 事件流是长连接，不受 http.Server.WriteTimeout 限制
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	if b.opt.Retry > 0 {
+		fmt.Fprintf(w, "retry: %d\n\n", b.opt.Retry.Milliseconds())
+	}
+	for _, e := range replay {
+		writeEvent(w, e)
+	}
+	if err := rc.Flush(); err != nil {
+		return ErrStreamingUnsupported
+	}
+
+	heartbeat := time.NewTicker(b.opt.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case e := <-s.ch:
+			writeEvent(w, e)
+			// 合并已排队的事件后再 flush
+			for n := len(s.ch); n > 0; n-- {
+				writeEvent(w, <-s.ch)
+			}
+		case <-heartbeat.C:
+			_, _ = w.Write([]byte(": ping\n\n"))
+		case <-s.kicked:
+			return ErrSlowConsumer
+		case <-r.Context().Done():
+			return nil
+		case <-b.done:
+			return nil
+		}
+		if err := rc.Flush(); err != nil {
+			return nil
+		}
+	}
+}
+
+// writeEvent 按 SSE 格式输出，多行 data 拆成多个 data: 行
+func writeEvent(w http.ResponseWriter, e Event) {
+	var sb strings.Builder
+	if e.ID != "" {
+		sb.WriteString("id: " + e.ID + "\n")
+	}
+	if e.Event != "" {
+		sb.WriteString("event: " + e.Event + "\n")
+	}
+	for _, line := range strings.Split(e.Data, "\n") {
+		sb.WriteString("data: " + strings.TrimSuffix(line, "\r") + "\n")
+	}
+	sb.WriteString("\n")
+	_, _ = w.Write([]byte(sb.String()))
+}
+
+func newID() string {
+	return uuid.NewString()
+}
@@ -0,0 +1,68 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultRedisChannel Redis 广播使用的默认频道
+const DefaultRedisChannel = "nomoyu:sse"
+
+// RedisFanout 通过 Redis pub/sub 在多实例间广播事件，任意实例 Publish 的事件所有实例都会收到
+type RedisFanout struct {
+	client  func() (redis.UniversalClient, error)
+	channel string
+}
+
+type redisMessage struct {
+	Topic string `json:"topic"`
+	Event Event  `json:"event"`
+}
+
+// NewRedisFanout client 在第一次使用时调用（如 redisx.Client），channel 为空时使用 DefaultRedisChannel
+func NewRedisFanout(client func() (redis.UniversalClient, error), channel string) *RedisFanout {
+	if channel == "" {
+		channel = DefaultRedisChannel
+	}
+	return &RedisFanout{client: client, channel: channel}
+}
+
+func (f *RedisFanout) Publish(ctx context.Context, topic string, e Event) error {
+	rdb, err := f.client()
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(redisMessage{Topic: topic, Event: e})
+	if err != nil {
+		return err
+	}
+	return rdb.Publish(ctx, f.channel, payload).Err()
+}
+
+func (f *RedisFanout) Subscribe(ctx context.Context, ready func(), deliver func(topic string, e Event)) error {
+	rdb, err := f.client()
+	if err != nil {
+		return err
+	}
+	sub := rdb.Subscribe(ctx, f.channel)
+	defer sub.Close()
+	// 等待订阅确认，之后发布的消息不会丢失
+	if _, err := sub.Receive(ctx); err != nil {
+		return err
+	}
+	ready()
+
+	for {
+		msg, err := sub.ReceiveMessage(ctx)
+		if err != nil {
+			return err
+		}
+		var m redisMessage
+		if err := json.Unmarshal([]byte(msg.Payload), &m); err != nil {
+			continue
+		}
+		deliver(m.Topic, m.Event)
+	}
+}
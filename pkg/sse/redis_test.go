@@ -0,0 +1,55 @@
+package sse_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/nomoyu/go-gin-framework/pkg/sse"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisFanout(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	get := func() (redis.UniversalClient, error) { return client, nil }
+
+	b1 := sse.NewBroker(sse.Options{}).WithFanout(sse.NewRedisFanout(get, ""))
+	b2 := sse.NewBroker(sse.Options{}).WithFanout(sse.NewRedisFanout(get, ""))
+	defer b1.Close()
+	defer b2.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b2.Serve(w, r, nil, func(s *sse.Stream) error {
+			s.Subscribe("t")
+			return nil
+		})
+	}))
+	defer srv.Close()
+
+	resp, err := (&http.Client{Timeout: 5 * time.Second}).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	for deadline := time.Now().Add(time.Second); b2.Count() == 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// 等待订阅 redis 频道
+	time.Sleep(50 * time.Millisecond)
+
+	if err := b1.Publish(context.Background(), "t", sse.Event{Data: "from b1"}); err != nil {
+		t.Fatal(err)
+	}
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		if sc.Text() == "data: from b1" {
+			return
+		}
+	}
+	t.Fatalf("event not delivered through redis: %v", sc.Err())
+}
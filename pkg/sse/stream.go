@@ -0,0 +1,70 @@
+package sse
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// Stream 一个 SSE 客户端连接
+type Stream struct {
+	ID          string
+	User        map[string]interface{} // 请求的认证信息，未认证为 nil
+	Request     *http.Request
+	LastEventID string // 客户端重连时携带的 Last-Event-ID（或 query lastEventId）
+
+	broker  *Broker
+	pending []string // handler 中声明的主题
+	topics  []string // 已订阅的主题，由 broker.mu 保护
+	ch      chan Event
+
+	kickOnce sync.Once
+	kicked   chan struct{}
+}
+
+func newStream(b *Broker, r *http.Request, user map[string]interface{}) *Stream {
+	last := r.Header.Get("Last-Event-ID")
+	if last == "" {
+		// EventSource 无法自定义请求头，首次连接时可以用 query 指定
+		last = r.URL.Query().Get("lastEventId")
+	}
+	return &Stream{
+		ID:          newID(),
+		User:        user,
+		Request:     r,
+		LastEventID: last,
+		broker:      b,
+		ch:          make(chan Event, b.opt.ClientBuffer),
+		kicked:      make(chan struct{}),
+	}
+}
+
+// Subscribe 订阅主题（在 Handler 中调用），开始推送前先补发 Last-Event-ID 之后的事件
+func (s *Stream) Subscribe(topics ...string) {
+	s.pending = append(s.pending, topics...)
+}
+
+// Send 只向当前连接发送事件（不进入补发缓冲）
+func (s *Stream) Send(e Event) error {
+	select {
+	case <-s.kicked:
+		return ErrSlowConsumer
+	default:
+	}
+	s.enqueue(e)
+	return nil
+}
+
+// Context 客户端断开时结束
+func (s *Stream) Context() context.Context {
+	return s.Request.Context()
+}
+
+// enqueue 不阻塞投递；队列满时断开连接，客户端重连后通过 Last-Event-ID 补发
+func (s *Stream) enqueue(e Event) {
+	select {
+	case s.ch <- e:
+	default:
+		s.kickOnce.Do(func() { close(s.kicked) })
+	}
+}
@@ -0,0 +1,213 @@
+// Package static 基于 fs.FS（embed 或磁盘目录）提供静态文件：缓存头、ETag、预压缩 .br/.gz 变体选择
+package static
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	cacheNoCache   = "no-cache"
+	cacheImmutable = "public, max-age=31536000, immutable"
+)
+
+// hashedName 构建工具产出的带内容哈希的文件名，如 app.3f2a91bc.js、index-B7x2k9Qa.css
+var hashedName = regexp.MustCompile(`[.-]([A-Za-z0-9_]{8,})\.[A-Za-z0-9]+$`)
+
+type Options struct {
+	Index string // 目录默认文件，默认 index.html
+	// MaxAge 普通文件的缓存时间；为 0 时返回 no-cache（每次用 ETag 协商）
+	MaxAge time.Duration
+	// Immutable 判断文件是否带内容哈希，命中时缓存一年并标记 immutable；默认按文件名中的哈希段判断
+	Immutable func(name string) bool
+}
+
+// encodings 预压缩变体，按优先级排列
+var encodings = []struct{ name, ext string }{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+type Server struct {
+	fsys  fs.FS
+	opt   Options
+	etags sync.Map // etagKey -> string
+}
+
+type etagKey struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func New(fsys fs.FS, opt Options) *Server {
+	if opt.Index == "" {
+		opt.Index = "index.html"
+	}
+	if opt.Immutable == nil {
+		opt.Immutable = IsHashedName
+	}
+	return &Server{fsys: fsys, opt: opt}
+}
+
+// IsHashedName 文件名是否带内容哈希（哈希段需同时包含字母与数字，避免误判 my-component.js）
+func IsHashedName(name string) bool {
+	m := hashedName.FindStringSubmatch(path.Base(name))
+	if m == nil {
+		return false
+	}
+	return strings.ContainsAny(m[1], "0123456789") && strings.IndexFunc(m[1], func(r rune) bool {
+		return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+	}) >= 0
+}
+
+// Serve 返回 name（相对 fs 根目录的路径）对应的文件；目录返回其中的 Index。
+// 文件不存在时不写响应并返回 false，由调用方决定 404 或 SPA 回退
+func (s *Server) Serve(w http.ResponseWriter, r *http.Request, name string) bool {
+	name = cleanName(name)
+	info, err := fs.Stat(s.fsys, name)
+	if err == nil && info.IsDir() {
+		name = path.Join(name, s.opt.Index)
+		info, err = fs.Stat(s.fsys, name)
+	}
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return s.serveFile(w, r, name) == nil
+}
+
+// ServeIndex 返回根目录的 Index（SPA 回退）
+func (s *Server) ServeIndex(w http.ResponseWriter, r *http.Request) bool {
+	return s.serveFile(w, r, s.opt.Index) == nil
+}
+
+// serveFile 打开、读取文件都成功后才写响应头，失败时调用方（404 或 SPA 回退）拿到的是干净的响应头
+func (s *Server) serveFile(w http.ResponseWriter, r *http.Request, name string) error {
+	ctype := mime.TypeByExtension(path.Ext(name))
+	if ctype == "" {
+		ctype = "application/octet-stream"
+	}
+
+	// 优先选择客户端支持的预压缩变体
+	served, encoding := name, ""
+	varied := false
+	for _, enc := range encodings {
+		if _, err := fs.Stat(s.fsys, name+enc.ext); err != nil {
+			continue
+		}
+		varied = true
+		if acceptsEncoding(r.Header.Get("Accept-Encoding"), enc.name) {
+			served, encoding = name+enc.ext, enc.name
+			break
+		}
+	}
+
+	f, err := s.fsys.Open(served)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	content, err := readSeeker(f)
+	if err != nil {
+		return err
+	}
+	etag, err := s.etag(served, info, content)
+	if err != nil {
+		return err
+	}
+
+	h := w.Header()
+	h.Set("Cache-Control", s.cacheControl(name))
+	if encoding != "" {
+		h.Set("Content-Encoding", encoding)
+	}
+	if varied {
+		h.Add("Vary", "Accept-Encoding")
+	}
+	h.Set("ETag", etag)
+	h.Set("Content-Type", ctype)
+
+	// ServeContent 负责 If-None-Match / If-Modified-Since / Range
+	http.ServeContent(w, r, name, info.ModTime(), content)
+	return nil
+}
+
+func (s *Server) cacheControl(name string) string {
+	switch {
+	case path.Base(name) == s.opt.Index:
+		return cacheNoCache
+	case s.opt.Immutable(name):
+		return cacheImmutable
+	case s.opt.MaxAge > 0:
+		return fmt.Sprintf("public, max-age=%d", int(s.opt.MaxAge.Seconds()))
+	default:
+		return cacheNoCache
+	}
+}
+
+// etag 按内容计算强 ETag，以文件名+大小+修改时间缓存（embed 的修改时间为零值，内容不变）
+func (s *Server) etag(name string, info fs.FileInfo, content io.ReadSeeker) (string, error) {
+	key := etagKey{name: name, size: info.Size(), modTime: info.ModTime()}
+	if v, ok := s.etags.Load(key); ok {
+		return v.(string), nil
+	}
+	sum := sha256.New()
+	if _, err := io.Copy(sum, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := `"` + hex.EncodeToString(sum.Sum(nil)[:16]) + `"`
+	s.etags.Store(key, etag)
+	return etag, nil
+}
+
+func readSeeker(f fs.File) (io.ReadSeeker, error) {
+	if rs, ok := f.(io.ReadSeeker); ok {
+		return rs, nil
+	}
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(b), nil
+}
+
+// cleanName 转换为 fs.FS 要求的无前导 / 的路径，根目录为 "."
+func cleanName(name string) string {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+// acceptsEncoding 判断 Accept-Encoding 是否接受 enc（忽略 q=0）
+func acceptsEncoding(header, enc string) bool {
+	for _, part := range strings.Split(header, ",") {
+		token, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(token), enc) {
+			continue
+		}
+		q := strings.ReplaceAll(params, " ", "")
+		return q != "q=0" && q != "q=0.0" && q != "q=0.00" && q != "q=0.000"
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package tlsx
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/nomoyu/go-gin-framework/pkg/logger"
+)
+
+// CertReloader 持有当前证书，并在证书/私钥文件变化时自动重新加载（无需重启进程）
+type CertReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	certMod time.Time
+	keyMod  time.Time
+}
+
+// NewCertReloader 立即加载一次证书，失败时返回错误
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload 重新读取证书与私钥；失败时保留旧证书
+func (r *CertReloader) Reload() error {
+	certMod, keyMod, err := r.modTimes()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("tlsx: load key pair: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.certMod = certMod
+	r.keyMod = keyMod
+	r.mu.Unlock()
+	return nil
+}
+
+// GetCertificate 供 tls.Config.GetCertificate 使用
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.cert == nil {
+		return nil, errors.New("tlsx: certificate not loaded")
+	}
+	return r.cert, nil
+}
+
+// Watch 按 interval 轮询文件修改时间，变化后重新加载，直到 ctx 结束
+// （轮询而非 inotify，兼容 k8s secret 的符号链接切换）
+func (r *CertReloader) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				logger.Errorf("reload tls certificate failed, keep the old one: %v", err)
+				continue
+			}
+			logger.Infof("tls certificate reloaded: %s", r.certFile)
+		}
+	}
+}
+
+func (r *CertReloader) changed() bool {
+	certMod, keyMod, err := r.modTimes()
+	if err != nil {
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return !certMod.Equal(r.certMod) || !keyMod.Equal(r.keyMod)
+}
+
+func (r *CertReloader) modTimes() (time.Time, time.Time, error) {
+	ci, err := os.Stat(r.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("tlsx: stat cert file: %w", err)
+	}
+	ki, err := os.Stat(r.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("tlsx: stat key file: %w", err)
+	}
+	return ci.ModTime(), ki.ModTime(), nil
+}
+
+// LoadCertPool 读取 PEM 格式的 CA 证书（mTLS 校验客户端证书用）
+func LoadCertPool(caFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("tlsx: read ca file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("tlsx: no certificate found in %s", caFile)
+	}
+	return pool, nil
+}
+
+// ClientAuthDefault 未指定客户端认证策略：配置了客户端 CA 时为 RequireAndVerifyClientCert，否则为 NoClientCert。
+// 与显式的 none（tls.NoClientCert）区分开，避免 none + CA 被升级为强制校验
+const ClientAuthDefault tls.ClientAuthType = -1
+
+// ParseClientAuth 解析配置中的客户端认证策略，空字符串返回 ClientAuthDefault
+func ParseClientAuth(mode string) (tls.ClientAuthType, error) {
+	switch mode {
+	case "":
+		return ClientAuthDefault, nil
+	case "none":
+		return tls.NoClientCert, nil
+	case "request":
+		return tls.RequestClientCert, nil
+	case "require":
+		return tls.RequireAnyClientCert, nil
+	case "verify_if_given":
+		return tls.VerifyClientCertIfGiven, nil
+	case "require_and_verify":
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("tlsx: unsupported client_auth %q", mode)
+	}
+}
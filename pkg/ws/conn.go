@@ -0,0 +1,206 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// 消息类型，与 RFC 6455 一致
+const (
+	TextMessage   = websocket.TextMessage
+	BinaryMessage = websocket.BinaryMessage
+)
+
+type message struct {
+	typ  int
+	data []byte
+}
+
+// Conn 一个 WebSocket 连接；Send 系列方法并发安全，Read 只能在 handler 所在的 goroutine 调用
+type Conn struct {
+	ID      string
+	User    map[string]interface{} // 握手时的认证信息，未认证为 nil
+	Request *http.Request
+
+	hub   *Hub
+	ws    *websocket.Conn
+	send  chan message
+	rooms map[string]struct{} // 由 hub.mu 保护
+
+	closeOnce sync.Once
+	closing   chan struct{} // 请求关闭：writePump 发送关闭帧后退出
+	done      chan struct{} // writePump 已退出
+	closeMsg  []byte
+}
+
+func newConn(h *Hub, wsConn *websocket.Conn, r *http.Request, user map[string]interface{}) *Conn {
+	c := &Conn{
+		ID:      newID(),
+		User:    user,
+		Request: r,
+		hub:     h,
+		ws:      wsConn,
+		send:    make(chan message, h.opt.SendBuffer),
+		rooms:   map[string]struct{}{},
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	wsConn.SetReadLimit(h.opt.MaxMessageSize)
+	_ = wsConn.SetReadDeadline(time.Now().Add(2 * h.opt.PingInterval))
+	wsConn.SetPongHandler(func(string) error {
+		return wsConn.SetReadDeadline(time.Now().Add(2 * h.opt.PingInterval))
+	})
+	return c
+}
+
+// Hub 返回连接所属的 Hub，用于在 handler 中广播
+func (c *Conn) Hub() *Hub {
+	return c.hub
+}
+
+// Read 读取下一条消息；连接关闭、心跳超时或消息超长时返回错误
+func (c *Conn) Read() (messageType int, data []byte, err error) {
+	return c.ws.ReadMessage()
+}
+
+// ReadJSON 读取下一条消息并按 JSON 解码
+func (c *Conn) ReadJSON(v interface{}) error {
+	return c.ws.ReadJSON(v)
+}
+
+// Wait 只推送不接收的连接调用：丢弃客户端消息直到连接关闭（读循环同时负责处理 pong 与关闭帧）
+func (c *Conn) Wait() {
+	for {
+		if _, _, err := c.ws.NextReader(); err != nil {
+			return
+		}
+	}
+}
+
+// Send 发送文本消息（非阻塞）；发送队列满时断开连接并返回 ErrSlowConsumer
+func (c *Conn) Send(data []byte) error {
+	return c.enqueue(message{typ: TextMessage, data: data})
+}
+
+// SendBinary 发送二进制消息
+func (c *Conn) SendBinary(data []byte) error {
+	return c.enqueue(message{typ: BinaryMessage, data: data})
+}
+
+// SendJSON 以 JSON 文本消息发送 v
+func (c *Conn) SendJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Send(data)
+}
+
+func (c *Conn) enqueue(m message) error {
+	select {
+	case <-c.closing:
+		return ErrClosed
+	default:
+	}
+	select {
+	case c.send <- m:
+		return nil
+	default:
+		c.Close(websocket.CloseTryAgainLater, "slow consumer")
+		return ErrSlowConsumer
+	}
+}
+
+// Join 加入房间
+func (c *Conn) Join(room string) {
+	c.hub.join(c, room)
+}
+
+// Leave 离开房间
+func (c *Conn) Leave(room string) {
+	c.hub.mu.Lock()
+	c.hub.leave(c, room)
+	c.hub.mu.Unlock()
+}
+
+// Rooms 当前加入的房间
+func (c *Conn) Rooms() []string {
+	c.hub.mu.RLock()
+	defer c.hub.mu.RUnlock()
+	out := make([]string, 0, len(c.rooms))
+	for room := range c.rooms {
+		out = append(out, room)
+	}
+	return out
+}
+
+// Close 发送关闭帧（已排队的消息先发出）后断开连接，可重复调用
+func (c *Conn) Close(code int, reason string) {
+	c.closeOnce.Do(func() {
+		c.closeMsg = websocket.FormatCloseMessage(code, reason)
+		close(c.closing)
+	})
+}
+
+// closeNow 未启动 writePump 时直接发送关闭帧并断开
+func (c *Conn) closeNow(code int, reason string) {
+	_ = c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(c.hub.opt.WriteWait))
+	_ = c.ws.Close()
+}
+
+// writePump 唯一的写 goroutine：发送排队消息与定时 ping，关闭时在一个写超时内尽量发出剩余消息再发送关闭帧
+func (c *Conn) writePump() {
+	ticker := time.NewTicker(c.hub.opt.PingInterval)
+	defer func() {
+		ticker.Stop()
+		_ = c.ws.Close()
+		close(c.done)
+	}()
+
+	for {
+		select {
+		case m := <-c.send:
+			_ = c.ws.SetWriteDeadline(time.Now().Add(c.hub.opt.WriteWait))
+			if err := c.ws.WriteMessage(m.typ, m.data); err != nil {
+				c.abort()
+				return
+			}
+		case <-ticker.C:
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.hub.opt.WriteWait)); err != nil {
+				c.abort()
+				return
+			}
+		case <-c.closing:
+			deadline := time.Now().Add(c.hub.opt.WriteWait)
+			_ = c.ws.SetWriteDeadline(deadline)
+			c.flush()
+			_ = c.ws.WriteControl(websocket.CloseMessage, c.closeMsg, deadline)
+			return
+		}
+	}
+}
+
+// flush 发出队列中剩余的消息，写失败即放弃
+func (c *Conn) flush() {
+	for {
+		select {
+		case m := <-c.send:
+			if c.ws.WriteMessage(m.typ, m.data) != nil {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// abort 写失败（对端已断开）时标记关闭，不再发送关闭帧
+func (c *Conn) abort() {
+	c.closeOnce.Do(func() {
+		close(c.closing)
+	})
+}
@@ -0,0 +1,306 @@
+// Package ws WebSocket 连接管理：升级、心跳、发送缓冲（背压）、房间广播与停机时统一关闭
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// 框架默认值
+const (
+	defaultPingInterval   = 30 * time.Second
+	defaultWriteWait      = 10 * time.Second
+	defaultMaxMessageSize = 64 << 10
+	defaultSendBuffer     = 256
+)
+
+var (
+	ErrClosed       = errors.New("ws: connection closed")
+	ErrSlowConsumer = errors.New("ws: send buffer full")
+	ErrHubClosed    = errors.New("ws: hub closed")
+)
+
+type Options struct {
+	PingInterval time.Duration // 服务端 ping 间隔，默认 30s；超过 2 倍间隔未收到 pong 视为断开
+	WriteWait    time.Duration // 单次写超时，默认 10s
+	// MaxMessageSize 单条消息上限（字节），默认 64KB，超出时关闭连接
+	MaxMessageSize int64
+	// SendBuffer 每个连接的发送队列长度，默认 256；队列满说明客户端消费太慢，直接断开而不是阻塞广播
+	SendBuffer int
+	// AllowedOrigins 允许的 Origin，为空时只允许同源，"*" 允许任意来源
+	AllowedOrigins []string
+}
+
+// Handler 处理一个已升级的连接，返回后连接关闭；通常在其中循环调用 Read
+type Handler func(c *Conn)
+
+// Hub 管理所有连接与房间
+type Hub struct {
+	opt      Options
+	upgrader websocket.Upgrader
+
+	mu     sync.RWMutex
+	conns  map[*Conn]struct{}
+	rooms  map[string]map[*Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func NewHub(opt Options) *Hub {
+	if opt.PingInterval <= 0 {
+		opt.PingInterval = defaultPingInterval
+	}
+	if opt.WriteWait <= 0 {
+		opt.WriteWait = defaultWriteWait
+	}
+	if opt.MaxMessageSize <= 0 {
+		opt.MaxMessageSize = defaultMaxMessageSize
+	}
+	if opt.SendBuffer <= 0 {
+		opt.SendBuffer = defaultSendBuffer
+	}
+	h := &Hub{
+		opt:   opt,
+		conns: map[*Conn]struct{}{},
+		rooms: map[string]map[*Conn]struct{}{},
+	}
+	h.upgrader = websocket.Upgrader{
+		HandshakeTimeout: opt.WriteWait,
+		CheckOrigin:      h.checkOrigin,
+	}
+	return h
+}
+
+// Serve 升级请求并运行 handler，阻塞到连接关闭；user 为认证信息（可为 nil）
+//
+// 升级失败时已向客户端写出 HTTP 错误响应
+func (h *Hub) Serve(w http.ResponseWriter, r *http.Request, user map[string]interface{}, handler Handler) error {
+	h.mu.RLock()
+	closed := h.closed
+	h.mu.RUnlock()
+	if closed {
+		http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+		return ErrHubClosed
+	}
+
+	var header http.Header
+	if proto := bearerSubprotocol(r); proto != "" {
+		// 浏览器要求服务端回显一个客户端声明的子协议，否则握手失败
+		header = http.Header{"Sec-Websocket-Protocol": {proto}}
+	}
+	wsConn, err := h.upgrader.Upgrade(w, r, header)
+	if err != nil {
+		return err
+	}
+
+	c := newConn(h, wsConn, r, user)
+	if !h.add(c) {
+		c.closeNow(websocket.CloseGoingAway, "server is shutting down")
+		return ErrHubClosed
+	}
+	defer h.remove(c)
+
+	go c.writePump()
+	handler(c)
+	c.Close(websocket.CloseNormalClosure, "")
+	<-c.done
+	return nil
+}
+
+func (h *Hub) add(c *Conn) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	h.conns[c] = struct{}{}
+	h.wg.Add(1)
+	return true
+}
+
+func (h *Hub) remove(c *Conn) {
+	h.mu.Lock()
+	delete(h.conns, c)
+	for room := range c.rooms {
+		h.leave(c, room)
+	}
+	h.mu.Unlock()
+	h.wg.Done()
+}
+
+func (h *Hub) join(c *Conn, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.conns[c]; !ok {
+		return
+	}
+	members, ok := h.rooms[room]
+	if !ok {
+		members = map[*Conn]struct{}{}
+		h.rooms[room] = members
+	}
+	members[c] = struct{}{}
+	c.rooms[room] = struct{}{}
+}
+
+// leave 调用方需持有 h.mu
+func (h *Hub) leave(c *Conn, room string) {
+	delete(c.rooms, room)
+	if members, ok := h.rooms[room]; ok {
+		delete(members, c)
+		if len(members) == 0 {
+			delete(h.rooms, room)
+		}
+	}
+}
+
+// Count 当前连接数
+func (h *Hub) Count() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.conns)
+}
+
+// RoomCount 房间内的连接数
+func (h *Hub) RoomCount(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[room])
+}
+
+// Broadcast 向所有连接发送文本消息
+func (h *Hub) Broadcast(data []byte) {
+	h.each(h.snapshot(""), data)
+}
+
+// BroadcastRoom 向房间内所有连接发送文本消息
+func (h *Hub) BroadcastRoom(room string, data []byte) {
+	h.each(h.snapshot(room), data)
+}
+
+// BroadcastJSON 向所有连接发送 JSON；room 为空表示全部连接
+func (h *Hub) BroadcastJSON(room string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	h.each(h.snapshot(room), data)
+	return nil
+}
+
+// Each 遍历当前连接（如按 User 定向推送），fn 中不要阻塞
+func (h *Hub) Each(fn func(c *Conn)) {
+	for _, c := range h.snapshot("") {
+		fn(c)
+	}
+}
+
+func (h *Hub) snapshot(room string) []*Conn {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	set := h.conns
+	if room != "" {
+		set = h.rooms[room]
+	}
+	out := make([]*Conn, 0, len(set))
+	for c := range set {
+		out = append(out, c)
+	}
+	return out
+}
+
+// each 广播不等待慢连接：发送队列满的连接会被断开
+func (h *Hub) each(conns []*Conn, data []byte) {
+	for _, c := range conns {
+		_ = c.Send(data)
+	}
+}
+
+// Close 拒绝新连接，向所有连接发送 1001(going away) 关闭帧并等待 handler 退出；
+// ctx 到期后强制断开剩余连接
+func (h *Hub) Close(ctx context.Context) error {
+	h.mu.Lock()
+	h.closed = true
+	h.mu.Unlock()
+
+	for _, c := range h.snapshot("") {
+		c.Close(websocket.CloseGoingAway, "server is shutting down")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		for _, c := range h.snapshot("") {
+			_ = c.ws.Close()
+		}
+		return ctx.Err()
+	}
+}
+
+func (h *Hub) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if len(h.opt.AllowedOrigins) == 0 {
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+	for _, allowed := range h.opt.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// SubprotocolBearer 浏览器无法给 WebSocket 设置请求头，可以通过子协议携带 token：
+// new WebSocket(url, ["bearer", token])
+const SubprotocolBearer = "bearer"
+
+// TokenFromRequest 取握手请求中的 token：Authorization 头 > 子协议 bearer,<token> > query access_token/token
+func TokenFromRequest(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	protos := websocket.Subprotocols(r)
+	for i := 0; i+1 < len(protos); i++ {
+		if strings.EqualFold(protos[i], SubprotocolBearer) {
+			return protos[i+1]
+		}
+	}
+	q := r.URL.Query()
+	if t := q.Get("access_token"); t != "" {
+		return t
+	}
+	return q.Get("token")
+}
+
+// bearerSubprotocol 客户端声明了 bearer 子协议时返回它（供握手响应回显）
+func bearerSubprotocol(r *http.Request) string {
+	for _, p := range websocket.Subprotocols(r) {
+		if strings.EqualFold(p, SubprotocolBearer) {
+			return p
+		}
+	}
+	return ""
+}
+
+func newID() string {
+	return uuid.NewString()
+}